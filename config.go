@@ -0,0 +1,162 @@
+package structflag
+
+import (
+	"flag"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+var fileFormats = struct {
+	sync.RWMutex
+	m map[string]func(data []byte) (map[string]interface{}, error)
+}{m: map[string]func(data []byte) (map[string]interface{}, error){".json": decodeJSON}}
+
+// RegisterFileFormat 为扩展名为 ext（例如 ".yaml"）的配置文件注册解码函数，供 ParseWithConfig 使用。
+// 解码函数的要求与 LoadFileWith 相同。".json" 是内置的；导入 yamlfile 包和 tomlfile 包
+// 会分别注册 ".yaml"、".yml" 和 ".toml"。对同一扩展名重复注册将替换之前的解码函数。
+func RegisterFileFormat(ext string, decode func(data []byte) (map[string]interface{}, error)) {
+	fileFormats.Lock()
+	defer fileFormats.Unlock()
+	fileFormats.m[strings.ToLower(ext)] = decode
+}
+
+// WithConfigFlag 注册一个名为 name 的字符串标志，用于指定配置文件的路径，由 ParseWithConfig 读取。
+// 该名称与字段的标志名称冲突时报告错误。
+func WithConfigFlag(name string) Option {
+	return func(o *options) {
+		o.configFlag = name
+	}
+}
+
+// ParseWithConfig 分两个阶段解析 args：首先在 args 中查找 WithConfigFlag 注册的配置文件标志，
+// 例如 "-config path"、"--config=path"，如果找到则按照 LoadFile 的规则将该文件读取到 v 中，
+// 再使用 args 解析 fs，因此命令行中的标志优先于配置文件，配置文件优先于 "default" 标签。
+//
+// 配置文件标志可以出现在其他标志之后；出现多次时使用最后一个。没有指定配置文件时跳过读取文件的阶段。
+// 配置文件的格式由扩展名决定，见 RegisterFileFormat，其他扩展名按照 JSON 解析。
+// 配置文件不能设置配置文件标志本身。
+//
+// v 必须是此前使用 WithConfigFlag 选项通过 LoadTo 加载到 fs 上的结构体指针。
+// 读取配置文件后，v 的各个标志的默认值会更新为文件中的值，以便用法信息显示实际的默认值。
+func ParseWithConfig(fs *flag.FlagSet, v interface{}, args []string) error {
+	opts := configOptions(fs)
+	if opts == nil {
+		return fmt.Errorf("structflag: no config flag is loaded on the FlagSet")
+	}
+	args, err := parseArgs(fs, opts, args)
+	if err != nil {
+		return err
+	}
+	if path, ok := findFlag(fs, args, opts.configFlag); ok {
+		if err := loadConfigFile("ParseWithConfig", fs, v, opts, path); err != nil {
+			return err
+		}
+	}
+	return fs.Parse(args)
+}
+
+// WithFile 使 LoadTo 在注册标志之前按照 LoadFile 的规则读取配置文件 path，格式由扩展名决定
+// （见 RegisterFileFormat），其他扩展名按照 JSON 解析。文件中的值成为对应标志的默认值，
+// 并优先于 "default" 标签和 Defaulter，因此优先级从高到低依次为命令行中的标志、环境变量（见 ApplyEnv）、
+// 配置文件、"default" 标签和 Defaulter。无法读取文件或文件中有无效的值时，LoadTo 报告错误；
+// 文件不存在时返回的错误满足 errors.Is(err, os.ErrNotExist)。
+func WithFile(path string) Option {
+	return func(o *options) {
+		o.file = path
+	}
+}
+
+// fileDecoder 返回扩展名与 path 对应的解码函数，没有注册时使用 JSON。
+func fileDecoder(path string) func(data []byte) (map[string]interface{}, error) {
+	fileFormats.RLock()
+	defer fileFormats.RUnlock()
+	if decode := fileFormats.m[strings.ToLower(filepath.Ext(path))]; decode != nil {
+		return decode
+	}
+	return decodeJSON
+}
+
+// applyConfigFile 读取 WithFile 指定的配置文件，其中的值由 setDefaults 保留。
+func (l *loader) applyConfigFile() {
+	if l.opts.file == "" {
+		return
+	}
+	if err := l.readFile(l.opts.file, fileDecoder(l.opts.file)); err != nil {
+		l.fail(err)
+	}
+}
+
+// loadConfigFile 按照 opts 将配置文件 path 读取到 v 中，格式由扩展名决定，然后将 v 的各个标志的默认值更新为字段的值。
+func loadConfigFile(fn string, fs *flag.FlagSet, v interface{}, opts *options, path string) error {
+	l := newLoader(fn, flag.NewFlagSet("", flag.ContinueOnError), nil)
+	l.opts = opts
+	err := l.loadFile(path, v, fileDecoder(path))
+	sources := make(map[string]Source)
+	for _, f := range l.fields {
+		if f.source.Kind == SourceFile {
+			sources[f.path] = f.source
+		}
+	}
+	if err != nil {
+		return err
+	}
+	for _, f := range fieldsOf(fs, v) {
+		if s, ok := sources[f.path]; ok {
+			f.source = s
+		}
+		fl := fs.Lookup(f.name)
+		if fl == nil {
+			continue
+		}
+		def := unwrapFlag(fl).Value.String()
+		for _, name := range f.names() {
+			if fl := fs.Lookup(name); fl != nil {
+				fl.DefValue = def
+			}
+		}
+	}
+	return nil
+}
+
+// findFlag 在 args 中查找标志 name 的值，规则与 flag 包相同：遇到 "--" 或第一个非标志参数时停止。
+// 出现多次时返回最后一个值。
+func findFlag(fs *flag.FlagSet, args []string, name string) (value string, found bool) {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" || len(arg) < 2 || arg[0] != '-' {
+			break
+		}
+		n := strings.TrimPrefix(arg[1:], "-")
+		v, hasValue := "", false
+		if j := strings.IndexByte(n, '='); j >= 0 {
+			n, v, hasValue = n[:j], n[j+1:], true
+		}
+		if n == name {
+			if !hasValue {
+				if i+1 >= len(args) {
+					break
+				}
+				i++
+				v = args[i]
+			}
+			value, found = v, true
+			continue
+		}
+		// 非布尔标志的值作为下一个参数给出时跳过该参数。
+		if fl := fs.Lookup(n); fl != nil && !hasValue {
+			if b, ok := fl.Value.(interface{ IsBoolFlag() bool }); !ok || !b.IsBoolFlag() {
+				i++
+			}
+		}
+	}
+	return value, found
+}
+
+// registerConfigFlag 在 fs 上注册 WithConfigFlag 指定的配置文件标志。
+func (l *loader) registerConfigFlag() {
+	if l.opts.configFlag != "" && l.fs.Lookup(l.opts.configFlag) == nil {
+		l.fs.String(l.opts.configFlag, "", "path to a config file")
+	}
+}
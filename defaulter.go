@@ -0,0 +1,33 @@
+package structflag
+
+import "reflect"
+
+// Defaulter 由需要在 Go 代码中计算默认值的类型实现，例如根据主机名或 CPU 数量得到的默认值。
+//
+// 加载结构体时，如果某个字段的指针实现了 Defaulter，则在注册标志之前调用其 SetDefault 方法，
+// 之后字段的值即作为标志的默认值。这同样适用于嵌套结构体以及传递给 LoadTo 的结构体本身；
+// 嵌套结构体及其字段先于包含它们的结构体调用，因此外层结构体可以覆盖字段自身计算的默认值。
+// SetDefault 先于 "default" 标签应用，因此带有 "default" 标签的字段仍使用标签中的默认值。
+// WithFile 指定的配置文件在 SetDefault 之后读取，因此文件中的值不会被 SetDefault 覆盖。
+// 被跳过的字段不会调用 SetDefault。
+type Defaulter interface {
+	SetDefault()
+}
+
+// addDefaulter 记录 v 的指针实现的 Defaulter，在注册之前由 applyDefaulters 调用。
+func (l *loader) addDefaulter(v reflect.Value) {
+	if !v.CanAddr() || !v.Addr().CanInterface() {
+		return
+	}
+	if d, ok := v.Addr().Interface().(Defaulter); ok {
+		l.defaulters = append(l.defaulters, d)
+	}
+}
+
+// applyDefaulters 按照记录的顺序调用 Defaulter 的 SetDefault 方法。WithFile 指定的配置文件在此之后读取，
+// 因此其中的值不会被 Defaulter 覆盖。
+func (l *loader) applyDefaulters() {
+	for _, d := range l.defaulters {
+		d.SetDefault()
+	}
+}
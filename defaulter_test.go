@@ -0,0 +1,35 @@
+package structflag
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type defaulterConfig struct {
+	Workers int    `flag:"workers"`
+	Host    string `flag:"host"`
+	Name    string `flag:"name"`
+}
+
+func (c *defaulterConfig) SetDefault() {
+	c.Workers = 4
+	c.Host = "localhost"
+	c.Name = "computed"
+}
+
+func TestDefaulterKeepsFileValues(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"workers": 16}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var cfg defaulterConfig
+	if err := TryLoadTo(flag.NewFlagSet("test", flag.ContinueOnError), "", &cfg, WithFile(path)); err != nil {
+		t.Fatalf("TryLoadTo: %v", err)
+	}
+	want := defaulterConfig{Workers: 16, Host: "localhost", Name: "computed"}
+	if cfg != want {
+		t.Errorf("got %+v, want %+v", cfg, want)
+	}
+}
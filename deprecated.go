@@ -0,0 +1,121 @@
+package structflag
+
+import (
+	"flag"
+	"fmt"
+)
+
+// deprecatedValue 包装已弃用字段的 flag.Value，在命令行中第一次设置该标志时向 fs 的输出打印一条警告。
+// 应用默认值（包括 Reset）不会触发警告。
+type deprecatedValue struct {
+	flag.Value
+	fs     *flag.FlagSet
+	name   string
+	msg    string
+	warned bool
+}
+
+func (v *deprecatedValue) Set(s string) error {
+	if !v.warned {
+		v.warned = true
+		fmt.Fprintf(v.fs.Output(), "warning: flag -%s is deprecated: %s\n", v.name, v.msg)
+	}
+	return v.Value.Set(s)
+}
+
+// SetDefault 应用默认值而不打印警告。
+func (v *deprecatedValue) SetDefault(s string) error {
+	if ds, ok := v.Value.(defaultSetter); ok {
+		return ds.SetDefault(s)
+	}
+	return v.Value.Set(s)
+}
+
+func (v *deprecatedValue) String() string {
+	if v == nil || v.Value == nil {
+		return ""
+	}
+	return v.Value.String()
+}
+
+func (v *deprecatedValue) IsBoolFlag() bool {
+	b, ok := v.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
+
+func (v *deprecatedValue) unwrap() flag.Value {
+	return v.Value
+}
+
+// rename 记录一次标志重命名的状态，由新旧名称的 renamedValue 共享。
+type rename struct {
+	fs       *flag.FlagSet
+	from, to string
+	fromSet  bool // 旧名称是否已在命令行中设置
+	toSet    bool // 新名称（包括其短选项和别名）是否已在命令行中设置
+}
+
+// renamedValue 包装重命名字段的 flag.Value。old 为 true 时表示旧名称，
+// 第一次设置时向 fs 的输出打印一条提示新名称的警告。新旧名称同时设置时报告错误。
+type renamedValue struct {
+	flag.Value
+	*rename
+	old bool
+}
+
+func (v *renamedValue) Set(s string) error {
+	if v.old {
+		if v.toSet {
+			return fmt.Errorf("-%s cannot be used together with its new name -%s", v.from, v.to)
+		}
+		if !v.fromSet {
+			fmt.Fprintf(v.fs.Output(), "warning: flag -%s has been renamed to -%s\n", v.from, v.to)
+		}
+		v.fromSet = true
+	} else {
+		if v.fromSet {
+			return fmt.Errorf("-%s cannot be used together with its old name -%s", v.to, v.from)
+		}
+		v.toSet = true
+	}
+	return v.Value.Set(s)
+}
+
+// SetDefault 应用默认值而不打印警告，也不记录为已设置。
+func (v *renamedValue) SetDefault(s string) error {
+	if ds, ok := v.Value.(defaultSetter); ok {
+		return ds.SetDefault(s)
+	}
+	return v.Value.Set(s)
+}
+
+func (v *renamedValue) String() string {
+	if v == nil || v.Value == nil {
+		return ""
+	}
+	return v.Value.String()
+}
+
+func (v *renamedValue) IsBoolFlag() bool {
+	b, ok := v.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
+
+func (v *renamedValue) unwrap() flag.Value {
+	return v.Value
+}
+
+// unwrapFlag 返回 fl 的一个副本，其值为被 deprecatedValue 或 renamedValue 包装的原始 flag.Value，
+// 以便用法信息按照原始类型显示参数名称和默认值。
+func unwrapFlag(fl *flag.Flag) *flag.Flag {
+	w, ok := fl.Value.(interface{ unwrap() flag.Value })
+	if !ok {
+		return fl
+	}
+	cp := *fl
+	for ok {
+		cp.Value = w.unwrap()
+		w, ok = cp.Value.(interface{ unwrap() flag.Value })
+	}
+	return &cp
+}
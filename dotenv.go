@@ -0,0 +1,139 @@
+package structflag
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// LoadDotEnv 读取 path 指向的 .env 文件，将其中的变量设置到进程的环境变量中，
+// 已经存在的环境变量不会被覆盖。按照惯例 .env 文件是可选的，因此文件不存在时 LoadDotEnv
+// 不做任何事情并返回 nil；如果文件必须存在，请使用 LoadDotEnvStrict。
+//
+// 文件的每一行为 KEY=VALUE 的形式，可以带有 "export " 前缀。空行和以 "#" 开头的行被忽略，
+// 未加引号的值中以空白开头的 "#" 之后的内容视为注释。值可以用双引号括起来，其中支持
+// \n、\t、\" 和 \\ 转义；也可以用单引号括起来，其中的内容按原样使用。
+// 格式错误的行会报告其行号。
+func LoadDotEnv(path string) error {
+	err := LoadDotEnvStrict(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// LoadDotEnvStrict 与 LoadDotEnv 相同，但文件不存在时返回满足 errors.Is(err, os.ErrNotExist) 的错误。
+func LoadDotEnvStrict(path string) error {
+	vars, err := readDotEnv(path)
+	if err != nil {
+		return err
+	}
+	for _, kv := range vars {
+		if _, ok := os.LookupEnv(kv[0]); ok {
+			continue
+		}
+		if err := os.Setenv(kv[0], kv[1]); err != nil {
+			return fmt.Errorf("structflag: set %s: %w", kv[0], err)
+		}
+	}
+	return nil
+}
+
+// readDotEnv 读取并解析 path 指向的 .env 文件，按照出现的顺序返回其中的变量。
+func readDotEnv(path string) ([][2]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("structflag: %w", err)
+	}
+	vars, err := parseDotEnv(data)
+	if err != nil {
+		return nil, fmt.Errorf("structflag: %s: %w", path, err)
+	}
+	return vars, nil
+}
+
+// parseDotEnv 解析 .env 文件的内容，按照出现的顺序返回其中的变量。
+func parseDotEnv(data []byte) ([][2]string, error) {
+	var vars [][2]string
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		i := strings.IndexByte(line, '=')
+		if i < 0 {
+			return nil, fmt.Errorf("line %d: missing '='", n)
+		}
+		key := strings.TrimSpace(line[:i])
+		if key == "" || strings.ContainsAny(key, " \t") {
+			return nil, fmt.Errorf("line %d: invalid variable name %q", n, key)
+		}
+		value, err := parseDotEnvValue(strings.TrimSpace(line[i+1:]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", n, err)
+		}
+		vars = append(vars, [2]string{key, value})
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return vars, nil
+}
+
+// parseDotEnvValue 解析 .env 文件中等号之后的值。
+func parseDotEnvValue(s string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+	switch s[0] {
+	case '\'':
+		end := strings.IndexByte(s[1:], '\'')
+		if end < 0 {
+			return "", errors.New("unterminated single quote")
+		}
+		return s[1 : end+1], checkTrailing(s[end+2:])
+	case '"':
+		var b strings.Builder
+		for i := 1; i < len(s); i++ {
+			switch c := s[i]; c {
+			case '"':
+				return b.String(), checkTrailing(s[i+1:])
+			case '\\':
+				if i+1 == len(s) {
+					return "", errors.New("unterminated double quote")
+				}
+				i++
+				switch s[i] {
+				case 'n':
+					b.WriteByte('\n')
+				case 't':
+					b.WriteByte('\t')
+				default:
+					b.WriteByte(s[i])
+				}
+			default:
+				b.WriteByte(c)
+			}
+		}
+		return "", errors.New("unterminated double quote")
+	}
+	for i := 1; i < len(s); i++ {
+		if s[i] == '#' && (s[i-1] == ' ' || s[i-1] == '\t') {
+			return strings.TrimSpace(s[:i]), nil
+		}
+	}
+	return s, nil
+}
+
+// checkTrailing 检查引号括起来的值之后是否只有空白或注释。
+func checkTrailing(s string) error {
+	if s = strings.TrimSpace(s); s != "" && !strings.HasPrefix(s, "#") {
+		return fmt.Errorf("unexpected %q after quoted value", s)
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package structflag
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// redacted 代替敏感字段的值出现在 Dump、Schema 和用法信息中。
+const redacted = "<redacted>"
+
+// Dump 按照字段顺序将 v 的每个标志的当前值以 "-name=value" 的形式逐行写入 w，
+// 例如用于在启动时记录实际使用的配置。包含空白、引号或为空的值会被加上引号。
+// 每行末尾以注释的形式注明值的来源（见 ValueSources），例如 "-port=80  # environment APP_PORT"，
+// 因此输出仍可作为响应文件使用。
+//
+// 带有 `sensitive:"true"` 标签的字段（包括带有该标签的嵌套结构体中的所有字段）的值写作 "<redacted>"，
+// 例如 "-password=<redacted>"；字段实际的值不受影响。
+//
+// Dump 应在 fs.Parse 之后调用，v 必须是此前通过 LoadTo 加载到 fs 上的结构体指针。
+func Dump(fs *flag.FlagSet, v interface{}, w io.Writer) error {
+	sources := fieldSources(fs, v)
+	for _, f := range fieldsOf(fs, v) {
+		fl := fs.Lookup(f.name)
+		if fl == nil {
+			continue
+		}
+		value := unwrapFlag(fl).Value.String()
+		if f.sensitive {
+			value = redacted
+		} else if value == "" || strings.ContainsAny(value, " \t\r\n\"'") {
+			value = strconv.Quote(value)
+		}
+		if _, err := fmt.Fprintf(w, "-%s=%s  # %s\n", f.name, value, sources[f]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+package structflag
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// ApplyEnv 使用环境变量设置 fs 上由 structflag 注册的标志，这些环境变量通过 "env" 标签指定，例如：
+//
+//	Port int `flag:"port" env:"APP_PORT" default:"8080"`
+//
+// 对于已设置的环境变量，ApplyEnv 将其值作为对应标志的默认值，因此命令行中显式设置的标志仍然优先，
+// 其次是环境变量，最后才是 "default" 标签。ApplyEnv 应在 fs.Parse 之前调用：
+//
+//	structflag.ApplyEnv(fs)
+//	fs.Parse(os.Args[1:])
+//
+// 使用 WithDotEnv 加载的标志还会查找 .env 文件中的变量，进程的环境变量优先于文件。
+// 环境变量在调用 ApplyEnv 时读取，而不是在加载时读取。Load 和 ParseAndValidate 会在解析之前自动调用 ApplyEnv。
+// 无法解析的环境变量会被报告为错误，错误信息中包含环境变量和标志的名称；有多个错误时使用 errors.Join 合并。
+func ApplyEnv(fs *flag.FlagSet) error {
+	var errs []error
+	files := make(dotEnvFiles)
+	for _, f := range fieldsOf(fs, nil) {
+		if f.env == "" {
+			continue
+		}
+		value, ok, err := files.lookup(f.env, f.dotEnv)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		value = f.argument(value)
+		fl := fs.Lookup(f.name)
+		if fl == nil {
+			continue
+		}
+		v := unwrapFlag(fl).Value
+		set := v.Set
+		if ds, ok := v.(defaultSetter); ok {
+			set = ds.SetDefault
+		}
+		if err := set(value); err != nil {
+			errs = append(errs, fmt.Errorf("structflag: invalid value %q for environment variable %s of flag -%s: %v", value, f.env, f.name, err))
+			continue
+		}
+		f.source = Source{Kind: SourceEnv, Name: f.env}
+		for _, name := range f.names() {
+			if fl := fs.Lookup(name); fl != nil {
+				fl.DefValue = v.String()
+			}
+		}
+	}
+	return joinErrors(errs)
+}
+
+// envOnlyField 是同时带有 `flag:"-"` 和 "env" 标签的字段，不生成标志，只从环境变量读取。
+type envOnlyField struct {
+	path  string
+	env   string
+	value reflect.Value
+}
+
+// applyEnvOnly 使用环境变量设置只从环境变量读取的字段。未设置的环境变量不改变字段。
+func (l *loader) applyEnvOnly() {
+	files := make(dotEnvFiles)
+	for _, e := range l.envOnly {
+		if !e.value.CanSet() {
+			l.fail(fmt.Errorf("structflag: %q tag on unexported field %s has no effect", "env", e.path))
+			continue
+		}
+		value, ok, err := files.lookup(e.env, l.opts.dotEnv)
+		if err != nil {
+			l.fail(err)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if err := setEnvOnly(e.value, value); err != nil {
+			l.fail(fmt.Errorf("structflag: invalid value %q for environment variable %s of field %s: %v", value, e.env, e.path, err))
+		}
+	}
+}
+
+// setEnvOnly 按照与标志相同的规则将 s 设置到字段 fv，切片字段的 s 为逗号分隔的列表。
+func setEnvOnly(fv reflect.Value, s string) error {
+	if v := newValue(fv); v != nil {
+		if ds, ok := v.(defaultSetter); ok {
+			return ds.SetDefault(s)
+		}
+		return v.Set(s)
+	}
+	if !isScalar(fv.Type()) {
+		return fmt.Errorf("unsupported type %s", fv.Type())
+	}
+	v, err := parseScalar(fv.Type(), s)
+	if err != nil {
+		return err
+	}
+	fv.Set(v)
+	return nil
+}
+
+// dotEnvFiles 缓存 WithDotEnv 指定的 .env 文件中的变量，键为文件路径。
+type dotEnvFiles map[string]map[string]string
+
+// lookup 查找环境变量 key：进程的环境变量优先，其次是 .env 文件 path（为空表示没有）中的变量。
+// .env 文件不存在时被忽略，格式错误时返回错误；每个文件的错误只返回一次，之后视为空文件。
+func (files dotEnvFiles) lookup(key, path string) (string, bool, error) {
+	if value, ok := os.LookupEnv(key); ok || path == "" {
+		return value, ok, nil
+	}
+	vars, ok := files[path]
+	if !ok {
+		list, err := readDotEnv(path)
+		vars = make(map[string]string, len(list))
+		files[path] = vars
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return "", false, err
+		}
+		for _, kv := range list {
+			vars[kv[0]] = kv[1]
+		}
+	}
+	value, ok := vars[key]
+	return value, ok, nil
+}
+
+// expandDefault 展开 "default" 标签中的 $NAME、${NAME} 和 ${NAME:-fallback}，"$$" 表示字面的 "$"，
+// 其他 "$" 原样保留。与 os.Expand 相同，$NAME 中的名称由字母、数字和下划线组成。
+// 与 shell 相同，fallback 在环境变量未设置或为空时使用。
+// 引用的环境变量未设置且没有 fallback 时返回错误。
+func expandDefault(s string) (string, error) {
+	if !strings.Contains(s, "$") {
+		return s, nil
+	}
+	var b strings.Builder
+	for {
+		i := strings.IndexByte(s, '$')
+		if i < 0 || i == len(s)-1 {
+			b.WriteString(s)
+			return b.String(), nil
+		}
+		b.WriteString(s[:i])
+		s = s[i:]
+		switch s[1] {
+		case '$':
+			b.WriteByte('$')
+			s = s[2:]
+			continue
+		case '{':
+		default:
+			j := 1
+			for j < len(s) && isNameByte(s[j]) {
+				j++
+			}
+			if j == 1 {
+				b.WriteByte('$')
+				s = s[1:]
+				continue
+			}
+			value, ok := os.LookupEnv(s[1:j])
+			if !ok {
+				return "", fmt.Errorf("environment variable %s is not set", s[1:j])
+			}
+			b.WriteString(value)
+			s = s[j:]
+			continue
+		}
+		j := strings.IndexByte(s, '}')
+		if j < 0 {
+			return "", fmt.Errorf("missing '}' in %q", s)
+		}
+		name, fallback, hasFallback := strings.Cut(s[2:j], ":-")
+		if name == "" {
+			return "", fmt.Errorf("empty variable name in %q", s[:j+1])
+		}
+		value, ok := os.LookupEnv(name)
+		switch {
+		case hasFallback && value == "":
+			value = fallback
+		case !ok:
+			return "", fmt.Errorf("environment variable %s is not set", name)
+		}
+		b.WriteString(value)
+		s = s[j+1:]
+	}
+}
+
+// isNameByte 报告 c 是否可以出现在 $NAME 形式的环境变量名称中。
+func isNameByte(c byte) bool {
+	return c == '_' || '0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
@@ -0,0 +1,75 @@
+package structflag
+
+import (
+	"flag"
+	"strings"
+	"testing"
+)
+
+func TestEnvOnlyField(t *testing.T) {
+	t.Setenv("STRUCTFLAG_TEST_SECRET_TOKEN", "s3cret")
+	var cfg struct {
+		Token string `flag:"-" env:"STRUCTFLAG_TEST_SECRET_TOKEN"`
+		Port  int    `flag:"-" env:"STRUCTFLAG_TEST_UNSET_PORT"`
+		Name  string `flag:"name"`
+	}
+	cfg.Port = 80
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	var buf strings.Builder
+	fs.SetOutput(&buf)
+	if err := TryLoadTo(fs, "", &cfg); err != nil {
+		t.Fatalf("TryLoadTo: %v", err)
+	}
+	var names []string
+	fs.VisitAll(func(fl *flag.Flag) { names = append(names, fl.Name) })
+	if len(names) != 1 || names[0] != "name" {
+		t.Errorf("registered flags = %q, want only name", names)
+	}
+	if cfg.Token != "s3cret" {
+		t.Errorf("Token = %q, want %q", cfg.Token, "s3cret")
+	}
+	if cfg.Port != 80 {
+		t.Errorf("Port = %d, want 80 when the variable is unset", cfg.Port)
+	}
+	fs.Usage()
+	if strings.Contains(buf.String(), "s3cret") || strings.Contains(buf.String(), "SECRET_TOKEN") {
+		t.Errorf("usage mentions the env-only field:\n%s", buf.String())
+	}
+
+	t.Setenv("STRUCTFLAG_TEST_UNSET_PORT", "http")
+	var bad struct {
+		Port int `flag:"-" env:"STRUCTFLAG_TEST_UNSET_PORT"`
+	}
+	if err := TryLoadTo(flag.NewFlagSet("test", flag.ContinueOnError), "", &bad); err == nil || !strings.Contains(err.Error(), "STRUCTFLAG_TEST_UNSET_PORT") {
+		t.Errorf("TryLoadTo with an invalid env value = %v", err)
+	}
+}
+
+func TestExpandDefault(t *testing.T) {
+	t.Setenv("STRUCTFLAG_TEST_HOME", "/home/u")
+	t.Setenv("STRUCTFLAG_TEST_EMPTY", "")
+	tests := []struct {
+		in, want string
+	}{
+		{"plain", "plain"},
+		{"${STRUCTFLAG_TEST_HOME}/.cache", "/home/u/.cache"},
+		{"$STRUCTFLAG_TEST_HOME/.cache", "/home/u/.cache"},
+		{"${STRUCTFLAG_TEST_UNSET:-/tmp}/work", "/tmp/work"},
+		{"${STRUCTFLAG_TEST_EMPTY:-fb}", "fb"},
+		{"$STRUCTFLAG_TEST_EMPTY", ""},
+		{"$$STRUCTFLAG_TEST_HOME", "$STRUCTFLAG_TEST_HOME"},
+		{"cost: 5$", "cost: 5$"},
+		{"$-x $ y", "$-x $ y"},
+	}
+	for _, tt := range tests {
+		got, err := expandDefault(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("expandDefault(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+	}
+	for _, in := range []string{"$STRUCTFLAG_TEST_UNSET", "${STRUCTFLAG_TEST_UNSET}", "${STRUCTFLAG_TEST_HOME", "${}"} {
+		if got, err := expandDefault(in); err == nil {
+			t.Errorf("expandDefault(%q) = %q, want an error", in, got)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package structflag
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+)
+
+// parseExpand 解析 "expand" 标签，报告是否展开字段 sf 的参数中的环境变量。目前只支持 `expand:"env"`，
+// 并且字段（或其元素）的类型必须为字符串。
+func parseExpand(sf reflect.StructField, path string) (bool, error) {
+	v, ok := sf.Tag.Lookup("expand")
+	if !ok {
+		return false, nil
+	}
+	if v != "env" {
+		return false, fmt.Errorf("structflag: invalid expand tag %q on field %s: want \"env\"", v, path)
+	}
+	elem := sf.Type
+	for elem.Kind() == reflect.Ptr || elem.Kind() == reflect.Slice || elem.Kind() == reflect.Array {
+		elem = elem.Elem()
+	}
+	if elem.Kind() != reflect.String {
+		return false, fmt.Errorf("structflag: expand tag on field %s requires a string type, got %s", path, sf.Type)
+	}
+	return true, nil
+}
+
+// expandEnv 展开 s 中的 $NAME 和 ${NAME}，"$$" 表示字面的 "$"。未设置的环境变量展开为空字符串。
+func expandEnv(s string) string {
+	return os.Expand(s, func(name string) string {
+		if name == "$" {
+			return "$"
+		}
+		return os.Getenv(name)
+	})
+}
+
+// argument 返回设置字段 f 时实际使用的参数：带有 `expand:"env"` 标签时先展开 s 中的环境变量，
+// 然后如果结果为 "aliases" 标签中的同义词，则替换为其对应的规范值。
+func (f *field) argument(s string) string {
+	if f.expandEnv {
+		s = expandEnv(s)
+	}
+	return f.canonicalValue(s)
+}
@@ -0,0 +1,32 @@
+package structflag
+
+import (
+	"flag"
+	"testing"
+)
+
+func TestExpandAndAliases(t *testing.T) {
+	t.Setenv("STRUCTFLAG_TEST_DATA", "/srv")
+	var cfg struct {
+		DataDir string `flag:"data-dir" expand:"env"`
+		Literal string `flag:"literal"`
+		Level   string `flag:"level" aliases:"warning=warn,err=error" default:"warning"`
+	}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	if err := TryLoadTo(fs, "", &cfg); err != nil {
+		t.Fatalf("TryLoadTo: %v", err)
+	}
+	if cfg.Level != "warn" {
+		t.Errorf("Level = %q after loading, want %q", cfg.Level, "warn")
+	}
+	args := []string{"-data-dir", "$STRUCTFLAG_TEST_DATA/$$x", "-literal", "$STRUCTFLAG_TEST_DATA", "-level", "err"}
+	if err := fs.Parse(args); err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if cfg.DataDir != "/srv/$x" || cfg.Literal != "$STRUCTFLAG_TEST_DATA" || cfg.Level != "error" {
+		t.Errorf("cfg = %+v", cfg)
+	}
+	if got := fs.Lookup("level").Value.String(); got != "error" {
+		t.Errorf("String() = %q, want %q", got, "error")
+	}
+}
@@ -0,0 +1,61 @@
+package structflag
+
+import (
+	"flag"
+	"reflect"
+)
+
+// ExportEnv 将 v 的当前配置导出为 "NAME=value" 形式的环境变量赋值，例如用于传递给子进程或 systemd 单元。
+//
+// 环境变量的名称与 WithEnvPrefix(prefix) 得到的相同，例如标志 db-host 对应 MYAPP_DB_HOST；
+// "env" 标签指定的名称优先，`env:"-"` 的字段不会被导出。值的格式与标志打印其值时相同，
+// 例如 time.Duration 为 "5s"，布尔值为 "true"，切片为逗号分隔的列表，因此可以由 ApplyEnv 读回。
+// 结果按照字段顺序排列；值为 nil 的指针字段不会被导出。
+//
+// 默认情况下敏感字段不会被导出，使用 IncludeSensitive 选项时导出其实际的值。
+// 使用 SkipDefaults 选项时跳过值等于默认值（"default" 标签的值、Defaulter 计算的值或零值）的字段。
+// 其他 opts 的含义与 LoadTo 相同。ExportEnv 不会修改 v；如果 v 无法加载，则返回 nil。
+func ExportEnv(v interface{}, prefix string, opts ...Option) []string {
+	opts = append(opts, WithEnvPrefix(prefix))
+	l := newLoader("ExportEnv", flag.NewFlagSet("", flag.ContinueOnError), opts)
+	if err := l.collect("", v); err != nil {
+		return nil
+	}
+	if err := l.err(); err != nil {
+		return nil
+	}
+
+	var defaults *loader
+	if l.opts.skipDefaults {
+		zero := reflect.New(reflect.TypeOf(v).Elem()).Interface()
+		var err error
+		if defaults, _, err = loadCopy("ExportEnv", "", zero, opts); err != nil {
+			return nil
+		}
+	}
+
+	var env []string
+	root := reflect.ValueOf(v).Elem()
+	for _, f := range l.fields {
+		if f.env == "" || (f.sensitive && !l.opts.includeSensitive) {
+			continue
+		}
+		fv := root.FieldByIndex(f.index)
+		if fv.Kind() == reflect.Ptr && fv.IsNil() {
+			continue
+		}
+		var value string
+		if f.value != nil {
+			value = f.value.String()
+		} else {
+			value = formatScalar(fv)
+		}
+		if defaults != nil {
+			if fl := defaults.fs.Lookup(f.name); fl != nil && unwrapFlag(fl).Value.String() == value {
+				continue
+			}
+		}
+		env = append(env, f.env+"="+value)
+	}
+	return env
+}
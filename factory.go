@@ -0,0 +1,100 @@
+package structflag
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var factories = struct {
+	sync.RWMutex
+	m map[reflect.Type]reflect.Value
+}{m: make(map[reflect.Type]reflect.Value)}
+
+// RegisterFactory 为接口类型注册一组按名称选择的实现。m 必须是 map[string]func() I 类型的映射，
+// 其中 I 为接口类型。此后加载的结构体中类型为 I 的字段将生成一个标志，其值为 m 的键，
+// 设置该标志时调用对应的函数创建实现并赋值给字段。未知的名称会报告错误并列出所有有效的名称。
+//
+// 对同一接口类型重复注册将替换之前的映射；m 为空映射时取消注册。如果 m 的类型不符合要求，
+// RegisterFactory 会引发 panic。例如：
+//
+//	structflag.RegisterFactory(map[string]func() Storer{
+//		"memory": func() Storer { return NewMemoryStore() },
+//		"redis":  func() Storer { return NewRedisStore() },
+//	})
+func RegisterFactory(m interface{}) {
+	rv := reflect.ValueOf(m)
+	if !rv.IsValid() {
+		panic("structflag: RegisterFactory requires a map[string]func() I, got nil")
+	}
+	t := rv.Type()
+	if t.Kind() != reflect.Map || t.Key().Kind() != reflect.String || t.Elem().Kind() != reflect.Func ||
+		t.Elem().NumIn() != 0 || t.Elem().NumOut() != 1 || t.Elem().Out(0).Kind() != reflect.Interface {
+		panic(fmt.Sprintf("structflag: RegisterFactory requires a map[string]func() I where I is an interface, got %s", t))
+	}
+	iface := t.Elem().Out(0)
+	factories.Lock()
+	defer factories.Unlock()
+	if rv.Len() == 0 {
+		delete(factories.m, iface)
+		return
+	}
+	factories.m[iface] = rv
+}
+
+func lookupFactory(t reflect.Type) (reflect.Value, bool) {
+	factories.RLock()
+	defer factories.RUnlock()
+	m, ok := factories.m[t]
+	return m, ok
+}
+
+// factoryValue 使用 RegisterFactory 注册的实现为接口类型的字段实现 flag.Value。
+type factoryValue struct {
+	field reflect.Value
+	m     reflect.Value
+	name  string // 最后一次选择的实现名称
+}
+
+func (v *factoryValue) Set(s string) error {
+	fn := v.m.MapIndex(reflect.ValueOf(s).Convert(v.m.Type().Key()))
+	if !fn.IsValid() {
+		return fmt.Errorf("unknown %s %q, valid values are: %s", v.field.Type(), s, strings.Join(v.names(), ", "))
+	}
+	x := fn.Call(nil)[0]
+	if !x.Type().AssignableTo(v.field.Type()) {
+		return fmt.Errorf("factory returned %s, want %s", x.Type(), v.field.Type())
+	}
+	v.field.Set(x)
+	v.name = s
+	return nil
+}
+
+// SetDefault 与 Set 相同，但空字符串将字段设为 nil，用于没有默认实现的字段。
+func (v *factoryValue) SetDefault(s string) error {
+	if s == "" {
+		v.field.Set(reflect.Zero(v.field.Type()))
+		v.name = ""
+		return nil
+	}
+	return v.Set(s)
+}
+
+func (v *factoryValue) String() string {
+	if v == nil {
+		return ""
+	}
+	return v.name
+}
+
+// names 返回所有有效的实现名称，按字母顺序排列。
+func (v *factoryValue) names() []string {
+	names := make([]string, 0, v.m.Len())
+	for _, k := range v.m.MapKeys() {
+		names = append(names, k.String())
+	}
+	sort.Strings(names)
+	return names
+}
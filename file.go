@@ -0,0 +1,316 @@
+package structflag
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// LoadFromJSON 读取 path 指向的 JSON 文件并将其解码到 v 中。
+//
+// 由于 LoadTo 使用字段的当前值作为标志的默认值，在 LoadTo 之前调用 LoadFromJSON
+// 即可让配置文件中的值成为默认值，并由命令行标志覆盖：
+//
+//	if err := structflag.LoadFromJSON("config.json", &cfg); err != nil && !errors.Is(err, os.ErrNotExist) {
+//		log.Fatal(err)
+//	}
+//	structflag.Load(&cfg)
+//
+// 注意，设置了 "default" 标签的字段仍以标签的值作为默认值，配置文件中该字段的值会被覆盖；
+// 需要配置文件优先于 "default" 标签时请使用 WithFile 选项。
+//
+// JSON 键按照 encoding/json 的规则与字段匹配，而不是按照标志名称；按照标志名称匹配请使用 LoadFile。
+// 如果文件不存在，返回的错误满足 errors.Is(err, os.ErrNotExist)，以便调用方将配置文件视为可选。
+//
+// YAML 配置文件由单独的 yamlfile 包中的 LoadFromYAML 支持，以免引入对 YAML 解码器的依赖。
+func LoadFromJSON(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("structflag: %w", err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("structflag: decode %s: %w", path, err)
+	}
+	return nil
+}
+
+// LoadFile 读取 path 指向的 JSON 文件，并按照标志名称将其中的值设置到 v 的字段中。
+//
+// 与 LoadFromJSON 不同，JSON 键与 structflag 为字段生成的标志名称（不含传递给 LoadTo 的前缀）匹配，
+// 因此配置文件与命令行使用相同的名称。opts 应与加载 v 时使用的相同。嵌套结构体可以写成嵌套的对象，
+// 也可以直接使用完整的标志名称，例如以下两种写法等价：
+//
+//	{"db": {"max-conns": 10}}
+//	{"db-max-conns": 10}
+//
+// 别名和旧名称同样可以用作键。字符串、数字和布尔值按照命令行参数的方式解析（例如 time.Duration
+// 字段使用 "250ms" 这样的字符串），切片字段对应 JSON 数组。
+// 与 LoadFromJSON 相同，在 LoadTo 之前调用 LoadFile 即可让配置文件中的值成为标志的默认值，
+// 但带有 "default" 标签的字段和 Defaulter 设置的字段仍会被覆盖。需要配置文件优先于 "default" 标签时，
+// 请在 LoadTo 中使用 WithFile 选项，或者在加载之后使用 ParseWithConfig 或 Resolve。
+//
+// 顶层的 "_comment" 键被忽略，用于保存 WriteSample 生成的用法信息。
+// 文件中所有无法匹配任何标志的键会被收集并一起报告（使用 IgnoreUnknownKeys 选项时被忽略）；类型不匹配的错误信息包含完整的键路径，
+// 例如 "db.max-conns"。有多个错误时使用 errors.Join 合并。
+// 如果文件不存在，返回的错误满足 errors.Is(err, os.ErrNotExist)，以便与格式错误的文件区分。
+//
+// 其他格式的配置文件可以通过 LoadFileWith 支持，例如 yamlfile 包和 tomlfile 包中的 LoadFile。
+func LoadFile(path string, v interface{}, opts ...Option) error {
+	return LoadFileWith(path, v, decodeJSON, opts...)
+}
+
+// decodeJSON 将 JSON 对象解码为 LoadFileWith 使用的映射，数字保留其原始文本。
+func decodeJSON(data []byte) (map[string]interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc map[string]interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// LoadFileWith 与 LoadFile 相同，但使用 decode 将文件的内容解码为映射，用于支持其他格式的配置文件。
+//
+// 映射中的值可以是字符串、布尔值、整数、浮点数、json.Number、time.Time、nil、
+// []interface{} 或嵌套的 map[string]interface{}。整数字段对应的值为浮点数时报告错误，而不是截断。
+// decode 返回的错误原样包装在错误信息中，以保留解码器提供的行号等信息。
+func LoadFileWith(path string, v interface{}, decode func(data []byte) (map[string]interface{}, error), opts ...Option) error {
+	return newLoader("LoadFileWith", flag.NewFlagSet("", flag.ContinueOnError), opts).loadFile(path, v, decode)
+}
+
+// loadFile 实现 LoadFileWith，按照加载器的选项匹配标志名称。
+func (l *loader) loadFile(path string, v interface{}, decode func(data []byte) (map[string]interface{}, error)) error {
+	if err := l.collect("", v); err != nil {
+		return err
+	}
+	if err := l.err(); err != nil {
+		return err
+	}
+	if err := l.readFile(path, decode); err != nil {
+		return err
+	}
+	return l.err()
+}
+
+// readFile 将配置文件 path 中的值设置到已收集的字段中，并将这些字段记录在 l.presets 中。
+// 无法读取或解码文件时返回错误，其他错误通过 l.fail 报告。
+func (l *loader) readFile(path string, decode func(data []byte) (map[string]interface{}, error)) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("structflag: %w", err)
+	}
+	doc, err := decode(data)
+	if err != nil {
+		return fmt.Errorf("structflag: decode %s: %w", path, err)
+	}
+
+	var unknown []string
+	l.applyFile(path, "", "", doc, l.keyNames(), &unknown)
+	if len(unknown) > 0 && !l.opts.ignoreUnknown {
+		l.fail(fmt.Errorf("structflag: unknown keys in %s: %s", path, strings.Join(unknown, ", ")))
+	}
+	return nil
+}
+
+// Apply 按照标志名称将 values 中的值设置到 v 的字段中，用于测试或来自键值存储的配置，
+// 而无需构造参数列表和 FlagSet。
+//
+// 与 LoadFile 相同，键为不含前缀的标志名称，别名和旧名称同样可以用作键；值按照命令行参数的方式解析，
+// 但每个值都是字段的完整值，例如切片字段使用逗号分隔的列表（与环境变量相同）。values 中没有的字段保持不变，"default" 标签不会被应用。
+// 无法匹配任何标志的键会被收集并一起报告，使用 IgnoreUnknownKeys 选项时则被忽略。
+// 所有无法解析的值都会被报告，有多个错误时使用 errors.Join 合并。opts 应与加载 v 时使用的相同。
+// 在 LoadTo 之前调用 Apply 时，设置的值与字段的其他初始值一样，会被 "default" 标签和 Defaulter 覆盖。
+func Apply(v interface{}, values map[string]string, opts ...Option) error {
+	l := newLoader("Apply", flag.NewFlagSet("", flag.ContinueOnError), opts)
+	if err := l.collect("", v); err != nil {
+		return err
+	}
+	if err := l.err(); err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	byName := l.keyNames()
+	var unknown []string
+	for _, key := range keys {
+		f := byName[l.canonical(key)]
+		if f == nil {
+			unknown = append(unknown, key)
+			continue
+		}
+		if err := f.set(values[key]); err != nil {
+			l.fail(fmt.Errorf("structflag: invalid value %q for key %s (field %s): %v", values[key], key, f.path, err))
+		}
+	}
+	if len(unknown) > 0 && !l.opts.ignoreUnknown {
+		l.fail(fmt.Errorf("structflag: unknown keys: %s", strings.Join(unknown, ", ")))
+	}
+	return l.err()
+}
+
+// keyNames 返回配置文件和 Apply 中的键可以使用的名称到字段的映射，包括标志名称、别名和旧名称。
+func (l *loader) keyNames() map[string]*field {
+	byName := make(map[string]*field)
+	for _, f := range l.fields {
+		byName[f.name] = f
+		for _, alias := range f.aliases {
+			byName[alias] = f
+		}
+		if f.renamedFrom != "" {
+			byName[f.renamedFrom] = f
+		}
+	}
+	return byName
+}
+
+// set 将 s 作为字段 f 的完整值设置，例如切片字段的 s 为逗号分隔的列表，与环境变量和 "default" 标签相同。
+func (f *field) set(s string) error {
+	s = f.argument(s)
+	if ds, ok := f.value.(defaultSetter); ok {
+		return ds.SetDefault(s)
+	}
+	if f.value != nil {
+		return f.value.Set(s)
+	}
+	ptr := reflect.ValueOf(f.ptr).Elem()
+	v, err := parseScalar(ptr.Type(), s)
+	if err != nil {
+		return err
+	}
+	ptr.Set(v)
+	return nil
+}
+
+// applyFile 将 doc 中的值设置到 byName 中对应的字段。prefix 为外层映射的键连接而成的标志名称，
+// keyPath 为以 "." 连接的键路径。无法匹配任何标志的键以其键路径追加到 unknown。
+func (l *loader) applyFile(path, prefix, keyPath string, doc map[string]interface{}, byName map[string]*field, unknown *[]string) {
+	keys := make([]string, 0, len(doc))
+	for key := range doc {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		name := l.canonical(l.joinName(prefix, key))
+		kp := key
+		if keyPath != "" {
+			kp = keyPath + "." + key
+		}
+		if prefix == "" && key == sampleKey {
+			continue
+		}
+		if prefix == "" && key == l.opts.configFlag && key != "" {
+			l.fail(fmt.Errorf("structflag: config file %s cannot set -%s", path, key))
+			continue
+		}
+		if f := byName[name]; f != nil {
+			if err := setFileValue(f, doc[key]); err != nil {
+				l.fail(fmt.Errorf("structflag: invalid value for key %s (field %s) in %s: %v", kp, f.path, path, err))
+			} else {
+				f.source = Source{Kind: SourceFile, Name: path + ":" + kp}
+				l.presets[f] = f.source
+			}
+			continue
+		}
+		if nested, ok := doc[key].(map[string]interface{}); ok {
+			l.applyFile(path, name, kp, nested, byName, unknown)
+			continue
+		}
+		*unknown = append(*unknown, kp)
+	}
+}
+
+// setFileValue 将配置文件中的值 x 设置到字段 f。nil 保持字段不变。
+func setFileValue(f *field, x interface{}) error {
+	elem := f.typ
+	for elem.Kind() == reflect.Ptr || elem.Kind() == reflect.Slice || elem.Kind() == reflect.Array {
+		elem = elem.Elem()
+	}
+	switch x := x.(type) {
+	case nil:
+		return nil
+	case map[string]interface{}:
+		return errors.New("unexpected mapping")
+	case time.Time:
+		if tv, ok := f.value.(*timeValue); ok {
+			*tv.p = x
+			return nil
+		}
+	case []interface{}:
+		elems := make([]string, len(x))
+		for i, item := range x {
+			s, err := fileText(item, elem)
+			if err != nil {
+				return fmt.Errorf("element %d: %v", i, err)
+			}
+			elems[i] = s
+		}
+		switch v := f.value.(type) {
+		case *sliceValue:
+			// 第一次 Set 会替换字段中原有的元素。
+			v.changed = false
+			for _, s := range elems {
+				if err := v.Set(s); err != nil {
+					return err
+				}
+			}
+			if len(elems) == 0 {
+				return v.SetDefault("")
+			}
+			return nil
+		case *arrayValue:
+			return v.Set(strings.Join(elems, ","))
+		}
+		return errors.New("unexpected sequence")
+	}
+	s, err := fileText(x, elem)
+	if err != nil {
+		return err
+	}
+	s = f.argument(s)
+	if f.value != nil {
+		if ds, ok := f.value.(defaultSetter); ok {
+			return ds.SetDefault(s)
+		}
+		return f.value.Set(s)
+	}
+	ptr := reflect.ValueOf(f.ptr).Elem()
+	v, err := parseScalar(ptr.Type(), s)
+	if err != nil {
+		return err
+	}
+	ptr.Set(v)
+	return nil
+}
+
+// fileText 返回配置文件中的标量 x 作为命令行参数时的文本。t 为字段（或其元素）的类型，
+// 用于拒绝整数字段对应的浮点数。
+func fileText(x interface{}, t reflect.Type) (string, error) {
+	switch x := x.(type) {
+	case string:
+		return x, nil
+	case json.Number:
+		return x.String(), nil
+	case float32, float64:
+		if isInteger(t) {
+			return "", fmt.Errorf("float %v for integer type %s", x, t)
+		}
+		return fmt.Sprint(x), nil
+	case time.Time:
+		return x.Format(time.RFC3339Nano), nil
+	case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprint(x), nil
+	}
+	return "", fmt.Errorf("unexpected %T", x)
+}
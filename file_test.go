@@ -0,0 +1,70 @@
+package structflag
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadFilePrecedence(t *testing.T) {
+	type config struct {
+		FromFile int `flag:"from-file" default:"8080"`
+		FromEnv  int `flag:"from-env" default:"8080" env:"STRUCTFLAG_TEST_FROM_ENV"`
+		FromFlag int `flag:"from-flag" default:"8080" env:"STRUCTFLAG_TEST_FROM_FLAG"`
+		Default  int `flag:"default" default:"8080"`
+	}
+	path := filepath.Join(t.TempDir(), "config.json")
+	data := `{"from-file": 9090, "from-env": 9090, "from-flag": 9090}`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("STRUCTFLAG_TEST_FROM_ENV", "7070")
+	t.Setenv("STRUCTFLAG_TEST_FROM_FLAG", "7070")
+
+	var cfg config
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	if err := TryLoadTo(fs, "", &cfg, WithFile(path)); err != nil {
+		t.Fatalf("TryLoadTo: %v", err)
+	}
+	if err := ApplyEnv(fs); err != nil {
+		t.Fatalf("ApplyEnv: %v", err)
+	}
+	if err := fs.Parse([]string{"-from-flag", "6060"}); err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	want := config{FromFile: 9090, FromEnv: 7070, FromFlag: 6060, Default: 8080}
+	if cfg != want {
+		t.Errorf("got %+v, want %+v", cfg, want)
+	}
+	if got := fs.Lookup("from-file").DefValue; got != "9090" {
+		t.Errorf("DefValue of -from-file = %q, want 9090", got)
+	}
+}
+
+func TestLoadFileDoesNotAffectLaterLoads(t *testing.T) {
+	type config struct {
+		Port int `flag:"port" default:"8080"`
+	}
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"port": 9090}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var cfg config
+	if err := LoadFile(path, &cfg); err != nil {
+		t.Fatalf("LoadFile: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		if err := TryLoadTo(fs, "", &cfg); err != nil {
+			t.Fatalf("TryLoadTo: %v", err)
+		}
+		if cfg.Port != 8080 {
+			t.Errorf("load %d: Port = %d, want 8080", i, cfg.Port)
+		}
+		if got := fs.Lookup("port").DefValue; got != "8080" {
+			t.Errorf("load %d: DefValue = %q, want 8080", i, got)
+		}
+	}
+}
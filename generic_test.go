@@ -0,0 +1,73 @@
+package structflag
+
+import (
+	"flag"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type genericConfig[T any] struct {
+	Value T `flag:"value" default:"5"`
+}
+
+func TestGenericStruct(t *testing.T) {
+	var ints genericConfig[int]
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	if err := TryLoadTo(fs, "", &ints); err != nil {
+		t.Fatalf("TryLoadTo: %v", err)
+	}
+	if err := fs.Parse([]string{"-value", "7"}); err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if ints.Value != 7 {
+		t.Errorf("Value = %d, want 7", ints.Value)
+	}
+
+	var durations genericConfig[time.Duration]
+	fs = flag.NewFlagSet("test", flag.ContinueOnError)
+	if err := TryLoadTo(fs, "", &durations); err == nil {
+		t.Error(`TryLoadTo accepted default "5" for time.Duration`)
+	}
+
+	var strs genericConfig[string]
+	fs = flag.NewFlagSet("test", flag.ContinueOnError)
+	if err := TryLoadTo(fs, "", &strs); err != nil {
+		t.Fatalf("TryLoadTo: %v", err)
+	}
+	if strs.Value != "5" {
+		t.Errorf("Value = %q, want %q", strs.Value, "5")
+	}
+
+	var ptrs genericConfig[*int]
+	fs = flag.NewFlagSet("test", flag.ContinueOnError)
+	if err := TryLoadTo(fs, "", &ptrs); err != nil {
+		t.Fatalf("TryLoadTo: %v", err)
+	}
+	if ptrs.Value == nil || *ptrs.Value != 5 {
+		t.Errorf("Value = %v, want 5", ptrs.Value)
+	}
+
+	var chans genericConfig[chan int]
+	fs = flag.NewFlagSet("test", flag.ContinueOnError)
+	skipped, err := LoadResult(fs, "", &chans)
+	if err != nil {
+		t.Fatalf("LoadResult: %v", err)
+	}
+	want := []Skipped{{FieldPath: "genericConfig[chan int].Value", Reason: SkipUnsupportedKind}}
+	if !reflect.DeepEqual(skipped, want) {
+		t.Errorf("LoadResult = %+v, want %+v", skipped, want)
+	}
+
+	var embedded struct {
+		genericConfig[uint]
+	}
+	fs = flag.NewFlagSet("test", flag.ContinueOnError)
+	if err := TryLoadTo(fs, "", &embedded); err != nil {
+		t.Fatalf("TryLoadTo: %v", err)
+	}
+	// 嵌入的泛型结构体按照不含类型参数的名称加载。
+	if fs.Lookup("genericConfig-value") == nil {
+		t.Error("flag genericConfig-value is not registered")
+	}
+}
@@ -0,0 +1,65 @@
+package structflag
+
+import (
+	"strings"
+	"unicode"
+)
+
+// KebabCase 将由字段名称得到的标志名称转换为 kebab-case，例如：
+//
+//	MaxIdleConns    -> max-idle-conns
+//	HTTPTimeout     -> http-timeout
+//	TLS13MinVersion -> tls13-min-version
+//
+// 连续的大写字母被视为一个缩写词，数字归属于其前面的单词。
+// 默认只转换字段的名称，嵌套结构体的前缀保持不变，除非同时设置了 WithConvertPrefixes。
+// 通过 "flag" 标签显式指定的名称保持不变。
+func KebabCase() Option {
+	return func(o *options) {
+		o.convertName = func(name string) string {
+			return joinWords(name, "-")
+		}
+	}
+}
+
+// SnakeCase 与 KebabCase 相同，但使用下划线连接单词，例如 MaxIdleConns 转换为 max_idle_conns。
+//
+// SnakeCase 只转换字段名称本身，不改变嵌套前缀与字段名称之间的分隔符。
+func SnakeCase() Option {
+	return func(o *options) {
+		o.convertName = func(name string) string {
+			return joinWords(name, "_")
+		}
+	}
+}
+
+// joinWords 将 name 拆分为单词，转换为小写后用 sep 连接。
+func joinWords(name, sep string) string {
+	words := splitWords(name)
+	for i, w := range words {
+		words[i] = strings.ToLower(w)
+	}
+	return strings.Join(words, sep)
+}
+
+// splitWords 按照 Go 标识符的命名习惯将 name 拆分为单词。
+//
+// 在以下位置拆分：小写字母或数字之后的大写字母之前，以及缩写词的最后一个大写字母之前
+// （当其后跟着小写字母时，例如 "HTTPTimeout" 中的 "T" 之前）。数字不会单独成词。
+func splitWords(name string) []string {
+	runes := []rune(name)
+	var words []string
+	start := 0
+	for i := 1; i < len(runes); i++ {
+		cur, prev := runes[i], runes[i-1]
+		if !unicode.IsUpper(cur) {
+			continue
+		}
+		if unicode.IsLower(prev) || unicode.IsDigit(prev) ||
+			(unicode.IsUpper(prev) && i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	return append(words, string(runes[start:]))
+}
@@ -0,0 +1,54 @@
+package structflag
+
+import (
+	"flag"
+	"testing"
+)
+
+func TestKebabCase(t *testing.T) {
+	tests := map[string]string{
+		"MaxIdleConns":    "max-idle-conns",
+		"HTTPTimeout":     "http-timeout",
+		"TLS13MinVersion": "tls13-min-version",
+		"ID":              "id",
+		"port":            "port",
+	}
+	for in, want := range tests {
+		if got := joinWords(in, "-"); got != want {
+			t.Errorf("joinWords(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestConvertPrefixes(t *testing.T) {
+	type config struct {
+		DBPool struct {
+			MaxConns int
+		}
+		Tagged struct {
+			MaxConns int
+		} `flag:"MyTagged"`
+	}
+	tests := []struct {
+		opts  []Option
+		names []string
+	}{
+		{[]Option{KebabCase()}, []string{"DBPool-max-conns", "MyTagged-max-conns"}},
+		{[]Option{KebabCase(), WithConvertPrefixes()}, []string{"db-pool-max-conns", "MyTagged-max-conns"}},
+		{[]Option{SnakeCase(), WithConvertPrefixes()}, []string{"db_pool-max_conns", "MyTagged-max_conns"}},
+		{[]Option{WithLowercase()}, []string{"DBPool-maxconns", "MyTagged-maxconns"}},
+	}
+	for _, tt := range tests {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		if err := TryLoadTo(fs, "", &config{}, tt.opts...); err != nil {
+			t.Fatalf("TryLoadTo: %v", err)
+		}
+		for _, name := range tt.names {
+			if fs.Lookup(name) == nil {
+				var got []string
+				fs.VisitAll(func(fl *flag.Flag) { got = append(got, fl.Name) })
+				t.Errorf("flag %s is not registered, got %q", name, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,339 @@
+package structflag
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Option 用于配置 Load、LoadTo 和 TryLoadTo 的行为。
+type Option func(*options)
+
+type options struct {
+	maxDepth              int
+	dropConflictingShorts bool
+	convertName           func(string) string // 转换由字段名称得到的标志名称
+	strictTags            bool
+	allowedTags           map[string]bool // 非 nil 时只允许 structflag 标签和其中的键
+	filter                func(reflect.StructField) bool
+	skipEmbeddedNonStruct bool
+	mapName               func(fieldPath []string) string
+	trimStructSuffix      string
+	onConflict            ConflictPolicy
+	separator             string // 连接前缀与名称的分隔符，为空时使用 "-"
+	flattenEmbedded       bool
+	usages                map[string]string // 标志名称 -> 用法信息
+	usage                 bool
+	groupedUsage          bool
+	convertPrefixes       bool
+	jsonNameFallback      bool
+	caseInsensitive       bool
+	translateUsage        func(key string) string
+	exclude               []string // 标志名称的 glob 模式
+	include               []string // 标志名称的 glob 模式，非空时只注册匹配的标志
+	autoShort             bool
+	autoEnv               bool
+	envPrefix             string
+	configFlag            string
+	dotEnv                string
+	ignoreUnknown         bool
+	setters               bool
+	skipDefaults          bool
+	includeSensitive      bool
+	responseFiles         bool
+	file                  string // WithFile 指定的配置文件
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithMaxDepth 限制嵌套结构体的最大深度，顶层结构体的深度为 1。
+// 超过该深度时将报告错误。n <= 0 表示不限制深度，这是默认行为。
+func WithMaxDepth(n int) Option {
+	return func(o *options) {
+		o.maxDepth = n
+	}
+}
+
+// WithDropConflictingShorts 使与其他标志冲突的短选项被静默丢弃，而不是报告错误。
+// 字段的长名称仍会正常注册。这适用于组合来自第三方包的结构体的情况。
+func WithDropConflictingShorts() Option {
+	return func(o *options) {
+		o.dropConflictingShorts = true
+	}
+}
+
+// WithLowercase 将由字段名称得到的标志名称转换为小写，例如 Verbose 变为 verbose。
+// 默认只转换字段的名称，嵌套结构体的前缀保持不变，除非同时设置了 WithConvertPrefixes。
+// 通过 "flag" 标签显式指定的名称保持不变。
+func WithLowercase() Option {
+	return func(o *options) {
+		o.convertName = strings.ToLower
+	}
+}
+
+// WithConvertPrefixes 使 WithLowercase、KebabCase 和 SnakeCase 等命名选项同时转换
+// 由嵌套结构体字段名称得到的前缀，例如 MaxConns 所在的嵌套结构体 DBPool 的前缀变为 db-pool。
+// 通过 "flag" 标签显式指定的前缀仍保持不变。
+func WithConvertPrefixes() Option {
+	return func(o *options) {
+		o.convertPrefixes = true
+	}
+}
+
+// WithJSONNameFallback 使没有 "flag" 标签名称的字段使用 "json" 标签中的名称（逗号之前的部分）
+// 作为标志名称，这同样适用于由嵌套结构体字段得到的前缀。与 "flag" 标签一样，
+// 这样得到的名称不会被 WithLowercase 等命名选项转换。`json:"-"` 的字段与 `flag:"-"` 一样被跳过，
+// 除非其 "flag" 标签指定了名称。"flag" 标签中的名称总是优先。
+func WithJSONNameFallback() Option {
+	return func(o *options) {
+		o.jsonNameFallback = true
+	}
+}
+
+// WithCaseInsensitive 将标志的长名称（包括别名和旧名称）转换为小写后注册，并允许 NormalizeArgs
+// 在解析之前将命令行中这些标志的名称转换为小写，使 -Port 和 --PORT=80 都能匹配 -port。
+// 短选项区分大小写，不受影响。
+func WithCaseInsensitive() Option {
+	return func(o *options) {
+		o.caseInsensitive = true
+	}
+}
+
+// WithStrictTags 启用严格模式：如果字段的标签中有与 structflag 标签拼写相近的未知键
+// （例如把 "usage" 写成了 "usge"），则报告错误，错误信息中包含字段路径。
+// json、yaml 等与 structflag 标签相差较远的键不受影响。
+func WithStrictTags() Option {
+	return func(o *options) {
+		o.strictTags = true
+	}
+}
+
+// WithAllowedTags 启用严格模式，并使用封闭的允许列表：除 structflag 自身的标签和 keys 之外，
+// 字段上出现的任何标签键都会报告错误。例如 WithAllowedTags("json", "yaml")。
+func WithAllowedTags(keys ...string) Option {
+	return func(o *options) {
+		o.strictTags = true
+		o.allowedTags = make(map[string]bool, len(keys))
+		for _, key := range keys {
+			o.allowedTags[key] = true
+		}
+	}
+}
+
+// WithFieldFilter 设置一个在运行时决定字段是否生成标志的谓词。
+// 对于 keep 返回 false 的字段，其行为与带有 `flag:"-"` 标签完全相同；对于嵌套结构体字段，
+// 其中的所有字段都会被跳过。keep 接收完整的 reflect.StructField，因此可以检查自定义标签，例如：
+//
+//	structflag.WithFieldFilter(func(f reflect.StructField) bool {
+//		return debug || f.Tag.Get("debug") != "true"
+//	})
+func WithFieldFilter(keep func(field reflect.StructField) bool) Option {
+	return func(o *options) {
+		o.filter = keep
+	}
+}
+
+// WithSkipEmbeddedNonStruct 跳过非结构体类型的嵌入字段（例如嵌入的 `type Count int`），
+// 而不是以其类型名称为它们生成标志。嵌入的结构体不受影响。
+func WithSkipEmbeddedNonStruct() Option {
+	return func(o *options) {
+		o.skipEmbeddedNonStruct = true
+	}
+}
+
+// WithNameMapper 使用 mapper 决定没有 "flag" 标签的字段的标志名称。
+//
+// mapper 接收从顶层结构体到该字段的名称链，例如字段 Config.DB.MaxConns 的名称链为
+// []string{"DB", "MaxConns"}；其中带有 "flag" 标签的嵌套结构体字段以标签的值出现在名称链中。
+// mapper 返回的名称即为完整的标志名称，嵌套结构体的前缀也完全由 mapper 控制，
+// 只有传递给 LoadTo 的前缀仍会加在其前面。带有 "flag" 标签的字段不经过 mapper，
+// 其名称仍为所在结构体的前缀加上标签的值，其中嵌套结构体的前缀同样由 mapper 得到。
+//
+// 设置 WithNameMapper 后，WithLowercase 等命名选项不再生效。
+func WithNameMapper(mapper func(fieldPath []string) string) Option {
+	return func(o *options) {
+		o.mapName = mapper
+	}
+}
+
+// WithTrimStructSuffix 在由嵌套结构体字段的名称得到前缀时去掉后缀 suffix，
+// 例如 WithTrimStructSuffix("Config") 使字段 ServerConfig 的前缀为 Server 而不是 ServerConfig。
+// 通过 "flag" 标签显式指定的名称保持不变。如果去掉后缀后名称为空（例如字段名称恰好为 Config），
+// 则使用原始的字段名称。设置了 WithNameMapper 时，名称链中的字段名称同样会去掉后缀。
+func WithTrimStructSuffix(suffix string) Option {
+	return func(o *options) {
+		o.trimStructSuffix = suffix
+	}
+}
+
+// WithSeparator 设置连接前缀与字段名称时使用的分隔符，默认为 "-"。
+// 分隔符用于所有层级的嵌套结构体以及传递给 LoadTo 的前缀，例如 WithSeparator(".")
+// 使结构体 DB 中嵌套的 Pool 的字段 Max 生成标志 "-db.pool.max"。
+// WithNameMapper 返回的名称由映射函数自行连接，只有传递给 LoadTo 的前缀使用该分隔符。
+func WithSeparator(sep string) Option {
+	return func(o *options) {
+		o.separator = sep
+	}
+}
+
+// WithFlattenEmbedded 使嵌入的结构体不增加前缀，其字段如同直接定义在外层结构体中，
+// 这与 Go 的字段提升以及 encoding/json 的行为一致。"flag" 标签中指定了名称的嵌入结构体不受影响。
+// 由此产生的名称冲突会像 "inline" 选项一样报告。默认情况下，嵌入的结构体仍以其类型名称为前缀。
+func WithFlattenEmbedded() Option {
+	return func(o *options) {
+		o.flattenEmbedded = true
+	}
+}
+
+// WithUsageMap 使用 m 中的用法信息代替 "usage" 标签，例如用于按语言翻译帮助文本。
+// m 的键为标志的完整名称，包括所有前缀；m 中没有的标志仍使用 "usage" 标签。
+// 对于嵌套结构体，m 中以其前缀为键的用法信息同样代替其 "usage" 标签，用作 PrintGrouped 的分组标题。
+func WithUsageMap(m map[string]string) Option {
+	return func(o *options) {
+		o.usages = m
+	}
+}
+
+// WithUsage 将 FlagSet 的用法函数设置为使用 PrintDefaults 打印所有标志，使短选项和别名与其长名称显示在同一行。
+// 对于 flag.CommandLine，设置的是 flag.Usage。不使用该选项时，LoadTo 不会改变用户的用法函数。
+func WithUsage() Option {
+	return func(o *options) {
+		o.usage = true
+	}
+}
+
+// WithGroupedUsage 与 WithUsage 类似，但用法函数使用 PrintByGroup 按 "group" 标签分组打印所有标志。
+func WithGroupedUsage() Option {
+	return func(o *options) {
+		o.groupedUsage = true
+	}
+}
+
+// WithUsageTranslator 使用 translate 翻译用法信息，例如用于本地化的帮助文本。
+//
+// "usage" 标签和 "deprecated" 标签的值被视为消息键，在注册之前交给 translate 翻译。
+// PrintDefaults、PrintGrouped 和 PrintByGroup 输出的由 structflag 生成的文本同样会被翻译，
+// 其消息键为 "default"、"e.g."、"DEPRECATED:"、"aliases:"、"derived:"、"formerly" 和 "env"。
+// translate 返回空字符串时使用原文。UsageProvider 和 WithUsageMap 提供的用法信息不会被翻译。
+func WithUsageTranslator(translate func(key string) string) Option {
+	return func(o *options) {
+		o.translateUsage = translate
+	}
+}
+
+// WithEnvPrefix 为没有 "env" 标签的每个标志根据其完整名称得到环境变量，由 ApplyEnv 读取：
+// 名称转换为大写，其中字母和数字以外的字符（例如 "-" 和 "."）替换为 "_"，再加上前缀 prefix 和 "_"。
+// 例如 WithEnvPrefix("MYAPP") 使标志 db-max-conns 对应环境变量 MYAPP_DB_MAX_CONNS。
+// prefix 为空时不加前缀。"env" 标签指定的环境变量优先，`env:"-"` 使字段没有对应的环境变量。
+// 两个标志对应同一个环境变量时报告错误。
+func WithEnvPrefix(prefix string) Option {
+	return func(o *options) {
+		o.autoEnv = true
+		o.envPrefix = prefix
+	}
+}
+
+// WithDotEnv 使 ApplyEnv 在查找 "env" 标签或 WithEnvPrefix 得到的环境变量时，同时查找 .env 文件 path 中的变量，
+// 而不修改进程的环境变量。进程的环境变量优先于文件，命令行中的标志优先于两者。
+// 文件的格式与 LoadDotEnv 相同；文件不存在时被忽略，格式错误的行由 ApplyEnv 报告其行号。
+func WithDotEnv(path string) Option {
+	return func(o *options) {
+		o.dotEnv = path
+	}
+}
+
+// AutoShort 为没有 "short" 标签的标志自动分配短选项：按照字段顺序，依次使用其名称中
+// 第一个未被占用的字母，例如 port 得到 p，之后的 prefix 得到 r。字母保持其在名称中的大小写，
+// 因此没有 "flag" 标签的字段 Port 得到 P。名称中的字母都已被占用时不分配短选项。
+// "short" 标签指定的短选项总是优先，不会被覆盖；隐藏的和已弃用的标志不会被分配短选项。
+// 分配结果只取决于结构体的字段顺序，因此同一个结构体总是得到相同的短选项。
+func AutoShort() Option {
+	return func(o *options) {
+		o.autoShort = true
+	}
+}
+
+// Exclude 跳过标志名称（包括所有前缀）与 patterns 中任一 glob 模式匹配的字段，
+// 其行为与带有 `flag:"-"` 标签完全相同。模式的语法与 path.Match 相同，例如 "metrics-*"。
+// 如果嵌套结构体的前缀与某个模式匹配，则跳过其中的所有字段。多次使用时模式会累加。
+func Exclude(patterns ...string) Option {
+	return func(o *options) {
+		o.exclude = append(o.exclude, patterns...)
+	}
+}
+
+// Include 只为标志名称（包括所有前缀）与 patterns 中任一 glob 模式匹配的字段生成标志，
+// 其他字段与带有 `flag:"-"` 标签一样被跳过。模式的语法与 path.Match 相同。
+// 嵌套结构体总是会被递归加载，以便包含其中匹配的字段；如果嵌套结构体的前缀与某个模式匹配，
+// 则包含其中的所有字段。同时设置 Exclude 时，被 Exclude 排除的字段总是被跳过。多次使用时模式会累加。
+func Include(patterns ...string) Option {
+	return func(o *options) {
+		o.include = append(o.include, patterns...)
+	}
+}
+
+// WithSetters 为带有 setter 方法的未导出字段生成标志。默认情况下未导出的字段总是被跳过；
+// 使用该选项后，如果结构体的指针具有名为 "Set" 加上首字母大写的字段名称、签名为 func(string) error 的
+// 导出方法，例如字段 timeout 的 SetTimeout(string) error，则为该字段注册标志，设置标志时调用该方法。
+// 标志的名称和标签与导出字段相同，默认值为字段的当前值；"count" 和 "layout" 标签不适用于这类字段。
+// 没有匹配的方法的未导出字段仍被跳过。由于这会将未导出的字段暴露为标志，因此需要显式启用。
+func WithSetters() Option {
+	return func(o *options) {
+		o.setters = true
+	}
+}
+
+// SkipDefaults 使 ExportEnv 跳过值等于默认值的字段。
+func SkipDefaults() Option {
+	return func(o *options) {
+		o.skipDefaults = true
+	}
+}
+
+// IncludeSensitive 使 ExportEnv 导出敏感字段的实际值，默认情况下这些字段不会被导出。
+func IncludeSensitive() Option {
+	return func(o *options) {
+		o.includeSensitive = true
+	}
+}
+
+// WithResponseFiles 使 Load、ParseAndValidate、ParseWithConfig 和 Resolve 在解析之前展开命令行中的
+// 响应文件（见 ExpandResponseFiles）。默认情况下以 "@" 开头的参数原样传递给 flag 包。
+func WithResponseFiles() Option {
+	return func(o *options) {
+		o.responseFiles = true
+	}
+}
+
+// IgnoreUnknownKeys 使 Apply 和 LoadFile 忽略无法匹配任何标志的键，而不是报告错误。
+func IgnoreUnknownKeys() Option {
+	return func(o *options) {
+		o.ignoreUnknown = true
+	}
+}
+
+// ConflictPolicy 决定字段的标志名称已在 FlagSet 中定义时的处理方式。
+type ConflictPolicy int
+
+const (
+	// ConflictError 报告错误，TryLoadTo 返回该错误而 LoadTo 引发 panic。这是默认行为。
+	ConflictError ConflictPolicy = iota
+	// ConflictSkip 保留 FlagSet 中已有的标志，不为该字段注册标志。
+	// 如果只有短选项冲突，则只丢弃短选项。
+	ConflictSkip
+	// ConflictPanic 立即引发 panic，即使通过 TryLoadTo 加载。
+	ConflictPanic
+)
+
+// OnConflict 设置字段的标志名称与 FlagSet 中已有的标志（例如手动注册的标志）冲突时的处理方式。
+// 同一次加载中的字段之间的冲突不受影响，总是报告错误。
+func OnConflict(policy ConflictPolicy) Option {
+	return func(o *options) {
+		o.onConflict = policy
+	}
+}
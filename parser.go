@@ -0,0 +1,76 @@
+package structflag
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// ParseFunc 将命令行参数解析为字段类型的值。
+type ParseFunc func(s string) (interface{}, error)
+
+var parsers = struct {
+	sync.RWMutex
+	m map[reflect.Type]ParseFunc
+}{m: make(map[reflect.Type]ParseFunc)}
+
+// RegisterParser 为类型 t 注册一个解析函数。此后加载的结构体中类型为 t 的字段将使用 parse
+// 解析命令行参数和 "default" 标签，parse 返回的值必须可以赋值给类型 t。
+//
+// 已注册的解析函数优先于此包内置的类型支持，因此也可以用于覆盖内置类型的解析方式。
+// 对同一类型重复注册将替换之前的解析函数；parse 为 nil 时取消注册。
+//
+// 例如：
+//
+//	structflag.RegisterParser(reflect.TypeOf(Level(0)), func(s string) (interface{}, error) {
+//		return ParseLevel(s)
+//	})
+func RegisterParser(t reflect.Type, parse ParseFunc) {
+	parsers.Lock()
+	defer parsers.Unlock()
+	if parse == nil {
+		delete(parsers.m, t)
+		return
+	}
+	parsers.m[t] = parse
+}
+
+func lookupParser(t reflect.Type) ParseFunc {
+	parsers.RLock()
+	defer parsers.RUnlock()
+	return parsers.m[t]
+}
+
+// parserValue 使用已注册的解析函数实现 flag.Value。
+type parserValue struct {
+	field reflect.Value
+	parse ParseFunc
+}
+
+func (v *parserValue) Set(s string) error {
+	x, err := v.parse(s)
+	if err != nil {
+		return err
+	}
+	rv := reflect.ValueOf(x)
+	if !rv.IsValid() {
+		v.field.Set(reflect.Zero(v.field.Type()))
+		return nil
+	}
+	if !rv.Type().AssignableTo(v.field.Type()) {
+		return fmt.Errorf("parser returned %s, want %s", rv.Type(), v.field.Type())
+	}
+	v.field.Set(rv)
+	return nil
+}
+
+func (v *parserValue) String() string {
+	if !v.field.IsValid() {
+		return ""
+	}
+	return fmt.Sprint(v.field.Interface())
+}
+
+func (v *parserValue) zeroString() string {
+	return fmt.Sprint(reflect.Zero(v.field.Type()).Interface())
+}
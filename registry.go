@@ -0,0 +1,101 @@
+package structflag
+
+import (
+	"flag"
+	"sync"
+)
+
+// registry 记录每个 FlagSet 上由 structflag 注册的标志，供 PrintDefaults 等函数查询。
+var registry = struct {
+	sync.Mutex
+	sets map[*flag.FlagSet]*flagSetInfo
+}{sets: make(map[*flag.FlagSet]*flagSetInfo)}
+
+// flagSetInfo 保存一个 FlagSet 上由 structflag 注册的字段。
+type flagSetInfo struct {
+	fields []*field                 // 按注册顺序排列
+	byName map[string]*field        // 标志名称（包括短选项）-> 字段
+	config *options                 // 使用 WithConfigFlag 加载时的选项
+	opts   map[interface{}]*options // 结构体指针 -> 加载时的选项
+}
+
+// record 将本次注册的标志记录到 registry 中。
+func (l *loader) record() {
+	registry.Lock()
+	defer registry.Unlock()
+	info := registry.sets[l.fs]
+	if info == nil {
+		info = &flagSetInfo{byName: make(map[string]*field), opts: make(map[interface{}]*options)}
+		registry.sets[l.fs] = info
+	}
+	if l.opts.configFlag != "" {
+		info.config = l.opts
+	}
+	for _, f := range l.fields {
+		info.opts[f.root] = l.opts
+		info.fields = append(info.fields, f)
+		for _, name := range f.names() {
+			info.byName[name] = f
+		}
+	}
+}
+
+// lookup 返回 fs 上名为 name 的标志所对应的字段。如果该标志不是由 structflag 注册的，则返回 nil。
+func lookup(fs *flag.FlagSet, name string) *field {
+	registry.Lock()
+	defer registry.Unlock()
+	if info := registry.sets[fs]; info != nil {
+		return info.byName[name]
+	}
+	return nil
+}
+
+// fieldsOf 按注册顺序返回 fs 上由 v 生成的字段。v 为 nil 时返回 fs 上所有由 structflag 注册的字段。
+func fieldsOf(fs *flag.FlagSet, v interface{}) []*field {
+	registry.Lock()
+	defer registry.Unlock()
+	info := registry.sets[fs]
+	if info == nil {
+		return nil
+	}
+	var fields []*field
+	for _, f := range info.fields {
+		if v == nil || f.root == v {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// dropLoaded 去掉此前已由 structflag 为同一字段注册到 fs 上的标志，使重复加载同一结构体成为幂等操作。
+func (l *loader) dropLoaded() {
+	fields := l.fields[:0]
+	for _, f := range l.fields {
+		prev := lookup(l.fs, f.name)
+		if prev != nil && prev.addr == f.addr && prev.path == f.path && l.fs.Lookup(f.name) != nil {
+			continue
+		}
+		fields = append(fields, f)
+	}
+	l.fields = fields
+}
+
+// loadOptions 返回 v 加载到 fs 上时的选项。如果 v 没有加载到 fs 上，则返回 nil。
+func loadOptions(fs *flag.FlagSet, v interface{}) *options {
+	registry.Lock()
+	defer registry.Unlock()
+	if info := registry.sets[fs]; info != nil {
+		return info.opts[v]
+	}
+	return nil
+}
+
+// configOptions 返回 fs 上使用 WithConfigFlag 加载时的选项。如果没有，则返回 nil。
+func configOptions(fs *flag.FlagSet) *options {
+	registry.Lock()
+	defer registry.Unlock()
+	if info := registry.sets[fs]; info != nil {
+		return info.config
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package structflag
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Change 描述 Reload 改变的一个字段。
+type Change struct {
+	Field string      // Go 字段路径，例如 "Config.DB.MaxConns"
+	Flag  string      // 标志的完整名称
+	Old   interface{} // 重新加载之前的值
+	New   interface{} // 重新加载之后的值
+}
+
+// Changes 按照字段顺序列出 Reload 改变的字段。
+type Changes []Change
+
+// Reloader 重新读取 ResolveReloader 使用的配置文件和环境变量，例如在收到 SIGHUP 时更新配置。
+// Reload 可以在多个 goroutine 中调用，但读取结构体的同步由调用方负责。
+type Reloader struct {
+	mu   sync.Mutex
+	fs   *flag.FlagSet
+	v    interface{}
+	env  bool
+	path string                // 读取的配置文件，为空表示没有
+	opts *options              // v 加载时的选项
+	base map[*field]fieldState // 应用配置文件和环境变量之前的状态
+}
+
+// fieldState 保存一个字段的值、对应标志的默认值和值的来源。
+type fieldState struct {
+	value  reflect.Value
+	def    string
+	source Source
+}
+
+// ResolveReloader 与 Resolve 相同，但同时返回一个 Reloader，以便之后重新读取配置文件和环境变量。
+// 即使返回错误，返回的 Reloader 仍然可用，例如配置文件暂时格式错误时可以在修复后重新加载。
+func ResolveReloader(fs *flag.FlagSet, v interface{}, src Sources) (*Reloader, error) {
+	opts := loadOptions(fs, v)
+	if opts == nil {
+		return nil, fmt.Errorf("structflag: ResolveReloader requires a struct loaded on the FlagSet, got %T", v)
+	}
+	r := &Reloader{fs: fs, v: v, env: src.Env, opts: opts}
+	r.base = r.snapshot()
+	path, err := resolve("ResolveReloader", fs, v, src)
+	r.path = path
+	return r, err
+}
+
+// Reload 重新读取配置文件和环境变量，按照与 Resolve 相同的优先级更新结构体，并返回值发生变化的字段。
+//
+// 命令行中显式设置的标志仍然优先，其字段不会被改变。配置文件中被删除的键和被取消设置的环境变量
+// 对应的字段恢复为 "default" 标签或字段加载时的值。命令行参数不会被重新解析。
+//
+// 如果读取配置文件或环境变量出错，结构体保持不变，Reload 返回 nil 和所有错误。
+// 通过 WithSetters 设置的未导出字段无法被恢复，也不会出现在返回的 Changes 中。
+func (r *Reloader) Reload() (Changes, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	old := r.snapshot()
+	set := make(map[*field]bool)
+	r.fs.Visit(func(fl *flag.Flag) {
+		if f := lookup(r.fs, fl.Name); f != nil && f.root == r.v {
+			set[f] = true
+		}
+	})
+	for f, s := range r.base {
+		if !set[f] {
+			r.restore(f, s)
+		}
+	}
+
+	var errs []error
+	if r.path != "" {
+		if err := loadConfigFile("Reload", r.fs, r.v, r.opts, r.path); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if r.env {
+		if err := ApplyEnv(r.fs); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for f := range set {
+		if s, ok := old[f]; ok {
+			r.restore(f, s)
+		}
+	}
+	if len(errs) > 0 {
+		for f, s := range old {
+			r.restore(f, s)
+		}
+		return nil, joinErrors(errs)
+	}
+
+	var changes Changes
+	root := reflect.ValueOf(r.v).Elem()
+	for _, f := range fieldsOf(r.fs, r.v) {
+		s, ok := old[f]
+		if !ok {
+			continue
+		}
+		cur := root.FieldByIndex(f.index)
+		if !reflect.DeepEqual(s.value.Interface(), cur.Interface()) {
+			changes = append(changes, Change{Field: f.path, Flag: f.name, Old: s.value.Interface(), New: cur.Interface()})
+		}
+	}
+	return changes, nil
+}
+
+// snapshot 保存 v 的每个可以读取的字段的当前状态。
+func (r *Reloader) snapshot() map[*field]fieldState {
+	states := make(map[*field]fieldState)
+	root := reflect.ValueOf(r.v).Elem()
+	for _, f := range fieldsOf(r.fs, r.v) {
+		fl := r.fs.Lookup(f.name)
+		fv := root.FieldByIndex(f.index)
+		if fl == nil || !fv.CanInterface() {
+			continue
+		}
+		value := reflect.New(fv.Type()).Elem()
+		value.Set(fv)
+		states[f] = fieldState{value: value, def: fl.DefValue, source: f.source}
+	}
+	return states
+}
+
+// restore 将字段 f 恢复为状态 s。
+func (r *Reloader) restore(f *field, s fieldState) {
+	reflect.ValueOf(r.v).Elem().FieldByIndex(f.index).Set(s.value)
+	for _, name := range f.names() {
+		if fl := r.fs.Lookup(name); fl != nil {
+			fl.DefValue = s.def
+		}
+	}
+	f.source = s.source
+}
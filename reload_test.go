@@ -0,0 +1,75 @@
+package structflag
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+type reloadConfig struct {
+	Port  int    `flag:"port" default:"80"`
+	Host  string `flag:"host" default:"localhost"`
+	Level string `flag:"level" default:"info"`
+	Token string `flag:"token" env:"STRUCTFLAG_TEST_RELOAD_TOKEN"`
+}
+
+func TestReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	write := func(data string) {
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(`{"port": 8080, "host": "example.com", "level": "debug"}`)
+	t.Setenv("STRUCTFLAG_TEST_RELOAD_TOKEN", "a")
+
+	var cfg reloadConfig
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	if err := TryLoadTo(fs, "", &cfg); err != nil {
+		t.Fatalf("TryLoadTo: %v", err)
+	}
+	r, err := ResolveReloader(fs, &cfg, Sources{File: path, Env: true, Args: []string{"-level", "warn"}})
+	if err != nil {
+		t.Fatalf("ResolveReloader: %v", err)
+	}
+	want := reloadConfig{Port: 8080, Host: "example.com", Level: "warn", Token: "a"}
+	if cfg != want {
+		t.Fatalf("cfg = %+v, want %+v", cfg, want)
+	}
+
+	// 修改端口，删除 host，修改命令行中设置的 level，并修改环境变量。
+	write(`{"port": 9090, "level": "error"}`)
+	t.Setenv("STRUCTFLAG_TEST_RELOAD_TOKEN", "b")
+	changes, err := r.Reload()
+	if err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	wantChanges := Changes{
+		{Field: "reloadConfig.Port", Flag: "port", Old: 8080, New: 9090},
+		{Field: "reloadConfig.Host", Flag: "host", Old: "example.com", New: "localhost"},
+		{Field: "reloadConfig.Token", Flag: "token", Old: "a", New: "b"},
+	}
+	if !reflect.DeepEqual(changes, wantChanges) {
+		t.Errorf("Reload = %+v, want %+v", changes, wantChanges)
+	}
+	want = reloadConfig{Port: 9090, Host: "localhost", Level: "warn", Token: "b"}
+	if cfg != want {
+		t.Errorf("cfg = %+v, want %+v", cfg, want)
+	}
+
+	// 配置文件格式错误时结构体保持不变。
+	write(`{"port": `)
+	if _, err := r.Reload(); err == nil {
+		t.Error("Reload did not report the malformed file")
+	}
+	if cfg != want {
+		t.Errorf("cfg after a failed Reload = %+v, want %+v", cfg, want)
+	}
+
+	write(`{"port": 9090}`)
+	if changes, err := r.Reload(); err != nil || len(changes) != 0 {
+		t.Errorf("Reload without edits = %+v, %v", changes, err)
+	}
+}
@@ -0,0 +1,71 @@
+package structflag
+
+import (
+	"flag"
+	"fmt"
+)
+
+// Sources 指定 Resolve 使用的配置来源。
+type Sources struct {
+	// File 为配置文件的路径，格式由扩展名决定（见 RegisterFileFormat）。
+	// 为空时，如果 v 使用 WithConfigFlag 加载，则使用 Args 中配置文件标志的值；否则不读取配置文件。
+	File string
+	// Env 为 true 时应用环境变量（见 ApplyEnv）。
+	Env bool
+	// Args 为命令行参数，例如 os.Args[1:]。v 使用 WithResponseFiles 加载时其中的响应文件会被展开。
+	Args []string
+}
+
+// Resolve 按照固定的优先级将 src 中的各个来源应用到 v，优先级从低到高依次为：
+//
+//  1. "default" 标签和字段加载时的值
+//  2. 配置文件（见 LoadFile）
+//  3. 环境变量（见 ApplyEnv）
+//  4. 命令行参数
+//
+// 每个来源只覆盖其提供的字段：配置文件中没有的键和未设置的环境变量不影响字段，
+// 而设置为空字符串的环境变量同样被视为提供了值。配置文件和环境变量的值会成为对应标志的默认值，
+// 以便用法信息显示实际的默认值。
+//
+// 某个来源出错时 Resolve 仍会继续应用其他来源，最后报告所有来源的错误，每个错误都标明其来源；
+// 有多个错误时使用 errors.Join 合并。v 必须是此前通过 LoadTo 加载到 fs 上的结构体指针。
+// 与 ApplyEnv 相同，环境变量会应用到 fs 上所有由 structflag 注册的标志。
+// 需要在运行时重新读取配置文件和环境变量时，使用 ResolveReloader。
+func Resolve(fs *flag.FlagSet, v interface{}, src Sources) error {
+	_, err := resolve("Resolve", fs, v, src)
+	return err
+}
+
+// resolve 实现 Resolve，并返回读取的配置文件的路径，没有读取配置文件时为空。
+func resolve(fn string, fs *flag.FlagSet, v interface{}, src Sources) (string, error) {
+	opts := loadOptions(fs, v)
+	if opts == nil {
+		return "", fmt.Errorf("structflag: %s requires a struct loaded on the FlagSet, got %T", fn, v)
+	}
+	var errs []error
+	args, err := parseArgs(fs, opts, src.Args)
+	if err != nil {
+		errs = append(errs, err)
+		args = nil
+	}
+
+	path := src.File
+	if path == "" && opts.configFlag != "" {
+		path, _ = findFlag(fs, args, opts.configFlag)
+	}
+	if path != "" {
+		if err := loadConfigFile(fn, fs, v, opts, path); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if src.Env {
+		if err := ApplyEnv(fs); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := fs.Parse(args); err != nil {
+		errs = append(errs, fmt.Errorf("structflag: command line: %w", err))
+	}
+
+	return path, joinErrors(errs)
+}
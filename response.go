@@ -0,0 +1,184 @@
+package structflag
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxResponseDepth 是响应文件嵌套的最大深度。
+const maxResponseDepth = 8
+
+// ExpandResponseFiles 返回 args 的副本，其中形如 "@path" 的参数被替换为文件 path 中的参数，
+// 用于绕过操作系统对命令行长度的限制。
+//
+// 文件中的参数以空白（包括换行）分隔。单引号或双引号括起的部分可以包含空白，双引号中和引号外的
+// 反斜杠转义下一个字符。以 "#" 开头的参数及其后直到行尾的内容是注释。
+// 文件中同样可以使用 "@path" 引用其他响应文件，最多嵌套 8 层，循环引用会报告错误。
+// 以 "@@" 开头的参数表示以 "@" 开头的字面参数，例如 "@@user" 变为 "@user"。
+// "--" 之后的参数（包括响应文件中的 "--" 之后的参数）原样保留。
+//
+// ExpandResponseFiles 不知道标志的类型，因此 "-name @value" 中的 "@value" 也会被展开。
+// 使用 WithResponseFiles 加载的结构体由 Load、ParseAndValidate、ParseWithConfig 和 Resolve
+// 在解析之前展开响应文件，此时非布尔标志的值作为下一个参数给出时不会被展开。
+func ExpandResponseFiles(args []string) ([]string, error) {
+	return expandResponseFiles(nil, args)
+}
+
+// expandResponseFiles 展开 args 中的响应文件。fs 不为 nil 时跳过其中非布尔标志的值。
+func expandResponseFiles(fs *flag.FlagSet, args []string) ([]string, error) {
+	e := &responseExpander{fs: fs}
+	return e.expand(args, nil)
+}
+
+// responseExpander 记录展开响应文件时的状态，该状态跨越响应文件的边界。
+type responseExpander struct {
+	fs       *flag.FlagSet
+	value    bool // 下一个参数是前一个标志的值
+	terminal bool // 已经遇到 "--"
+}
+
+// expand 展开 args 中的响应文件，stack 为正在展开的响应文件的绝对路径。
+func (e *responseExpander) expand(args []string, stack []string) ([]string, error) {
+	var out []string
+	for _, arg := range args {
+		switch {
+		case e.terminal:
+			out = append(out, arg)
+			continue
+		case e.value:
+			e.value = false
+			out = append(out, arg)
+			continue
+		case arg == "--":
+			e.terminal = true
+			out = append(out, arg)
+			continue
+		case strings.HasPrefix(arg, "@@"):
+			out = append(out, arg[1:])
+			continue
+		case !strings.HasPrefix(arg, "@") || arg == "@":
+			e.value = e.takesValue(arg)
+			out = append(out, arg)
+			continue
+		}
+		path := arg[1:]
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return nil, fmt.Errorf("structflag: response file %s: %w", path, err)
+		}
+		for _, p := range stack {
+			if p == abs {
+				return nil, fmt.Errorf("structflag: response file %s includes itself", path)
+			}
+		}
+		if len(stack) >= maxResponseDepth {
+			return nil, fmt.Errorf("structflag: response file %s: maximum nesting depth %d exceeded", path, maxResponseDepth)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("structflag: response file: %w", err)
+		}
+		tokens, err := splitResponseFile(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("structflag: response file %s: %v", path, err)
+		}
+		tokens, err = e.expand(tokens, append(stack, abs))
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, tokens...)
+	}
+	return out, nil
+}
+
+// takesValue 报告 arg 是否为 fs 上值作为下一个参数给出的非布尔标志。
+func (e *responseExpander) takesValue(arg string) bool {
+	if e.fs == nil || len(arg) < 2 || arg[0] != '-' {
+		return false
+	}
+	name := strings.TrimPrefix(arg[1:], "-")
+	if name == "" || strings.IndexByte(name, '=') >= 0 {
+		return false
+	}
+	fl := e.fs.Lookup(name)
+	if fl == nil {
+		return false
+	}
+	b, ok := fl.Value.(interface{ IsBoolFlag() bool })
+	return !ok || !b.IsBoolFlag()
+}
+
+// parseArgs 在 o 启用了 WithResponseFiles 时展开 args 中的响应文件，否则原样返回 args。
+func parseArgs(fs *flag.FlagSet, o *options, args []string) ([]string, error) {
+	if o == nil || !o.responseFiles {
+		return args, nil
+	}
+	return expandResponseFiles(fs, args)
+}
+
+// splitResponseFile 将响应文件的内容 s 分割为参数，规则见 ExpandResponseFiles。
+func splitResponseFile(s string) ([]string, error) {
+	var (
+		tokens  []string
+		b       strings.Builder
+		inToken bool
+		quote   rune
+		escaped bool
+		line    = 1
+		start   int // 当前引号开始的行号
+	)
+	for _, r := range s {
+		if r == '\n' {
+			line++
+		}
+		switch {
+		case escaped:
+			b.WriteRune(r)
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				b.WriteRune(r)
+			}
+		case quote == '"':
+			switch r {
+			case '"':
+				quote = 0
+			case '\\':
+				escaped = true
+			default:
+				b.WriteRune(r)
+			}
+		case r == '#' && !inToken:
+			quote = '#'
+		case quote == '#':
+			if r == '\n' {
+				quote = 0
+			}
+		case r == ' ' || r == '\t' || r == '\r' || r == '\n':
+			if inToken {
+				tokens = append(tokens, b.String())
+				b.Reset()
+				inToken = false
+			}
+		case r == '\'' || r == '"':
+			quote, start, inToken = r, line, true
+		case r == '\\':
+			escaped, inToken = true, true
+		default:
+			b.WriteRune(r)
+			inToken = true
+		}
+	}
+	if quote == '\'' || quote == '"' {
+		return nil, fmt.Errorf("line %d: unterminated quote", start)
+	}
+	if inToken {
+		tokens = append(tokens, b.String())
+	}
+	return tokens, nil
+}
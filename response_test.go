@@ -0,0 +1,75 @@
+package structflag
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestResponseFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "args")
+	if err := os.WriteFile(path, []byte("-port 8080 # comment\n-host 'a b'\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var cfg struct {
+		Name    string `flag:"name"`
+		Port    int    `flag:"port"`
+		Host    string `flag:"host"`
+		Verbose bool   `flag:"v"`
+	}
+
+	// 未启用 WithResponseFiles 时不展开。
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	if err := TryLoadTo(fs, "", &cfg); err != nil {
+		t.Fatalf("TryLoadTo: %v", err)
+	}
+	if err := ParseAndValidate(fs, []string{"@" + path}, &cfg); err != nil {
+		t.Fatalf("ParseAndValidate: %v", err)
+	}
+	if got := fs.Args(); !reflect.DeepEqual(got, []string{"@" + path}) {
+		t.Errorf("Args without WithResponseFiles = %q", got)
+	}
+
+	fs = flag.NewFlagSet("test", flag.ContinueOnError)
+	if err := TryLoadTo(fs, "", &cfg, WithResponseFiles()); err != nil {
+		t.Fatalf("TryLoadTo: %v", err)
+	}
+	args := []string{"-name", "@literal", "-v", "@" + path, "--", "@" + path}
+	if err := ParseAndValidate(fs, args, &cfg); err != nil {
+		t.Fatalf("ParseAndValidate: %v", err)
+	}
+	if cfg.Name != "@literal" || cfg.Port != 8080 || cfg.Host != "a b" || !cfg.Verbose {
+		t.Errorf("cfg = %+v", cfg)
+	}
+	if got := fs.Args(); !reflect.DeepEqual(got, []string{"@" + path}) {
+		t.Errorf("Args after -- = %q", got)
+	}
+}
+
+func TestExpandResponseFiles(t *testing.T) {
+	dir := t.TempDir()
+	a, b := filepath.Join(dir, "a"), filepath.Join(dir, "b")
+	if err := os.WriteFile(a, []byte("x @"+b+" --\n@"+b), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(b, []byte(`y "z w"`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ExpandResponseFiles([]string{"@@user", "@" + a, "@" + b})
+	if err != nil {
+		t.Fatalf("ExpandResponseFiles: %v", err)
+	}
+	want := []string{"@user", "x", "y", "z w", "--", "@" + b, "@" + b}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ExpandResponseFiles = %q, want %q", got, want)
+	}
+
+	if err := os.WriteFile(b, []byte("@"+a), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ExpandResponseFiles([]string{"@" + a}); err == nil {
+		t.Error("ExpandResponseFiles did not report a cycle")
+	}
+}
@@ -0,0 +1,154 @@
+package structflag
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Format 表示 WriteSample 生成的配置文件的格式。
+type Format int
+
+const (
+	// FormatJSON 表示 JSON 格式。
+	FormatJSON Format = iota
+	// FormatYAML 表示 YAML 格式。
+	FormatYAML
+)
+
+func (f Format) String() string {
+	switch f {
+	case FormatJSON:
+		return "json"
+	case FormatYAML:
+		return "yaml"
+	}
+	return "unknown"
+}
+
+// sampleKey 是 WriteSample 生成的 JSON 文件中保存用法信息的键，LoadFile 会忽略顶层的该键。
+const sampleKey = "_comment"
+
+// sampleEntry 是示例配置文件中的一个键。
+type sampleEntry struct {
+	key   string
+	value string // JSON 或 YAML 中的值
+	usage string
+}
+
+// WriteSample 将 v 的示例配置文件以 format 指定的格式写入 w，帮助用户编写自己的配置文件。
+//
+// 示例中的键为不含前缀的标志名称，值为加载 v 时各个标志的默认值（包括 "default" 标签和 Defaulter 的值），
+// 没有默认值的指针字段写作 null。YAML 格式中用法信息以注释的形式写在对应的键之前；
+// JSON 格式中用法信息写在顶层的 "_comment" 对象中，其键同样为标志名称，LoadFile 会忽略该对象。
+// 隐藏的、已弃用的、敏感的以及带有 "mask" 标签的字段不会出现在示例中。
+//
+// 使用 LoadFile（对于 YAML，yamlfile 包中的 LoadFile）读取生成的文件会得到与默认值相同的配置。
+// WriteSample 不会修改 v，也不会注册任何标志。opts 的含义与 LoadTo 相同。
+func WriteSample(w io.Writer, v interface{}, format Format, opts ...Option) error {
+	if format != FormatJSON && format != FormatYAML {
+		return fmt.Errorf("structflag: unknown sample format %d", int(format))
+	}
+	l, cp, err := loadCopy("WriteSample", "", v, opts)
+	if err != nil {
+		return err
+	}
+
+	var entries []sampleEntry
+	for _, f := range l.fields {
+		fl := l.fs.Lookup(f.name)
+		if fl == nil || f.hidden || f.deprecated != "" || f.sensitive || f.mask != "" {
+			continue
+		}
+		entries = append(entries, sampleEntry{
+			key:   f.name,
+			value: sampleValue(f, cp.Elem().FieldByIndex(f.index), unwrapFlag(fl).Value.String()),
+			usage: f.usage,
+		})
+	}
+
+	bw := bufio.NewWriter(w)
+	if format == FormatYAML {
+		for _, e := range entries {
+			if e.usage != "" {
+				for _, line := range strings.Split(e.usage, "\n") {
+					fmt.Fprintf(bw, "# %s\n", line)
+				}
+			}
+			fmt.Fprintf(bw, "%s: %s\n", yamlKey(e.key), e.value)
+		}
+		return bw.Flush()
+	}
+
+	fmt.Fprint(bw, "{\n")
+	var usages []string
+	for _, e := range entries {
+		if e.usage != "" {
+			usages = append(usages, fmt.Sprintf("    %s: %s", strconv.Quote(e.key), strconv.Quote(e.usage)))
+		}
+	}
+	if len(usages) > 0 {
+		fmt.Fprintf(bw, "  %q: {\n%s\n  }", sampleKey, strings.Join(usages, ",\n"))
+		if len(entries) > 0 {
+			fmt.Fprint(bw, ",")
+		}
+		fmt.Fprint(bw, "\n")
+	}
+	for i, e := range entries {
+		fmt.Fprintf(bw, "  %s: %s", strconv.Quote(e.key), e.value)
+		if i < len(entries)-1 {
+			fmt.Fprint(bw, ",")
+		}
+		fmt.Fprint(bw, "\n")
+	}
+	fmt.Fprint(bw, "}\n")
+	return bw.Flush()
+}
+
+// sampleValue 返回字段 f 在示例配置文件中的值，fv 为字段的值，def 为标志的默认值。
+// 返回的文本同时是合法的 JSON 和 YAML：布尔值和数字原样写出，切片写作字符串数组，其他值写作字符串。
+func sampleValue(f *field, fv reflect.Value, def string) string {
+	switch {
+	case fv.Kind() == reflect.Ptr && fv.IsNil():
+		return "null"
+	case fv.Kind() == reflect.Slice && f.value != nil && isScalar(fv.Type().Elem()):
+		elems := make([]string, fv.Len())
+		for i := range elems {
+			elems[i] = strconv.Quote(formatScalar(fv.Index(i)))
+		}
+		return "[" + strings.Join(elems, ", ") + "]"
+	}
+	if _, ok := f.value.(*scalarValue); (f.value == nil || ok) && fv.Type() != durationType {
+		switch fv.Kind() {
+		case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+			if json.Valid([]byte(def)) {
+				return def
+			}
+		}
+	}
+	return strconv.Quote(def)
+}
+
+// yamlKey 返回 YAML 中的键 key：只包含字母、数字、"-" 和 "_" 并以字母开头的键原样写出，
+// 其他键以及 "true"、"null" 等会被解析为其他类型的键加上引号。
+func yamlKey(key string) string {
+	switch strings.ToLower(key) {
+	case "true", "false", "null", "yes", "no", "on", "off", "y", "n":
+		return strconv.Quote(key)
+	}
+	for i, r := range key {
+		letter := r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z'
+		if !letter && (i == 0 || !(r >= '0' && r <= '9' || r == '-' || r == '_')) {
+			return strconv.Quote(key)
+		}
+	}
+	if key == "" {
+		return `""`
+	}
+	return key
+}
@@ -0,0 +1,195 @@
+package structflag
+
+import (
+	"encoding/json"
+	"flag"
+	"reflect"
+)
+
+// schemaFlag 描述 Schema 输出中的一个标志。
+type schemaFlag struct {
+	Name      string   `json:"name"`
+	Short     string   `json:"short,omitempty"`
+	Aliases   []string `json:"aliases,omitempty"`
+	Type      string   `json:"type"`
+	Usage     string   `json:"usage,omitempty"`
+	Example   string   `json:"example,omitempty"`
+	Default   string   `json:"default,omitempty"`
+	Env       string   `json:"env,omitempty"`
+	Required  bool     `json:"required,omitempty"`
+	Choices   []string `json:"choices,omitempty"`
+	Hidden    bool     `json:"hidden,omitempty"`
+	Sensitive bool     `json:"sensitive,omitempty"`
+	Field     string   `json:"field"`
+}
+
+// Schema 返回描述 v 将生成的所有标志的 JSON 文档，供生成文档和 shell 补全等工具使用。
+//
+// 文档的格式为 {"flags": [...]}，标志按照字段顺序排列，每个标志包含完整的名称（包含前缀）、
+// 短选项、别名、Go 类型、用法信息、示例值、默认值、环境变量、是否必需、可选值（例如 RegisterFactory 注册的名称）、
+// 是否隐藏以及 Go 字段路径。没有的属性会被省略。被 "show-default" 隐藏的默认值不会输出，
+// 带有 "mask" 标签的字段输出其占位符，带有 `sensitive:"true"` 标签的字段输出 "<redacted>"。
+//
+// Schema 不会修改 v，也不会注册任何标志。prefix 和 opts 的含义与 LoadTo 相同。
+func Schema(prefix string, v interface{}, opts ...Option) ([]byte, error) {
+	l, _, err := loadCopy("Schema", prefix, v, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	flags := make([]schemaFlag, 0, len(l.fields))
+	for _, f := range l.fields {
+		sf := schemaFlag{
+			Name:      f.name,
+			Short:     f.short,
+			Aliases:   f.aliases,
+			Type:      f.typ.String(),
+			Usage:     f.usage,
+			Example:   f.example,
+			Env:       f.env,
+			Required:  f.required,
+			Hidden:    f.hidden,
+			Sensitive: f.sensitive,
+			Field:     f.path,
+		}
+		if !f.hideDefault {
+			sf.Default = f.displayDefault(l.fs.Lookup(f.name))
+		}
+		sf.Choices = f.choices()
+		flags = append(flags, sf)
+	}
+	return json.MarshalIndent(struct {
+		Flags []schemaFlag `json:"flags"`
+	}{flags}, "", "  ")
+}
+
+// DefaultOf 返回 v 中标志名称（或别名）为 flagName 的字段在加载时得到的默认值，以及是否找到该标志。
+//
+// 默认值按照与加载时相同的优先级确定：如果设置了字段的环境变量（见 ApplyEnv，包括 WithDotEnv 指定的文件），则为环境变量的值；
+// 否则为 "default" 标签的值；否则为字段的当前值（包括 Defaulter 计算的值）。返回值的类型与字段相同。
+// 无法解析的环境变量被忽略。WithSetters 生成的未导出字段返回其值的字符串形式。flagName 不含前缀，opts 的含义与 LoadTo 相同。
+//
+// DefaultOf 不会修改 v，也不会注册任何标志。如果 v 无法加载，则返回 nil 和 false。
+func DefaultOf(v interface{}, flagName string, opts ...Option) (interface{}, bool) {
+	l, cp, err := loadCopy("DefaultOf", "", v, opts)
+	if err != nil {
+		return nil, false
+	}
+	for _, f := range l.fields {
+		if f.name != flagName && !contains(f.aliases, flagName) {
+			continue
+		}
+		if value, ok, _ := make(dotEnvFiles).lookup(f.env, f.dotEnv); ok && f.env != "" {
+			fl := unwrapFlag(l.fs.Lookup(f.name))
+			set := fl.Value.Set
+			if ds, ok := fl.Value.(defaultSetter); ok {
+				set = ds.SetDefault
+			}
+			if err := set(f.argument(value)); err != nil {
+				// 忽略无法解析的环境变量，恢复为注册时的默认值。
+				_ = set(fl.DefValue)
+			}
+		}
+		field := cp.Elem().FieldByIndex(f.index)
+		if !field.CanInterface() {
+			// 通过 setter 方法设置的未导出字段无法读取，返回其文本形式。
+			return unwrapFlag(l.fs.Lookup(f.name)).Value.String(), true
+		}
+		return field.Interface(), true
+	}
+	return nil, false
+}
+
+// loadCopy 将 v 的深拷贝加载到一个临时的 FlagSet 上，返回加载器和副本，以免应用默认值或调用 Defaulter 时修改 v
+// 及其中的指针、切片和映射指向的值。
+// 加载的标志不会被记录，因此不影响 PrintDefaults 等函数。
+func loadCopy(fn, prefix string, v interface{}, opts []Option) (*loader, reflect.Value, error) {
+	l := newLoader(fn, flag.NewFlagSet("", flag.ContinueOnError), opts)
+	val, err := l.structValue(v)
+	if err != nil {
+		return nil, reflect.Value{}, err
+	}
+	cp := reflect.New(val.Type())
+	cp.Elem().Set(deepCopy(val, make(map[copyKey]reflect.Value)))
+	if err := l.collect(prefix, cp.Interface()); err != nil {
+		return nil, reflect.Value{}, err
+	}
+	l.check()
+	l.applyDefaulters()
+	l.applyConfigFile()
+	l.setDefaults()
+	if err := l.err(); err != nil {
+		return nil, reflect.Value{}, err
+	}
+	l.register()
+	l.expandUsages()
+	return l, cp, nil
+}
+
+// copyKey 标识 deepCopy 已经复制过的指针，以便保留共享和循环引用。
+type copyKey struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+// deepCopy 返回 v 的深拷贝：导出字段中的指针、切片和映射指向新分配的副本。
+// 未导出的字段无法通过反射设置，按值复制，例如 time.Time 和 big.Int 的内部字段。
+func deepCopy(v reflect.Value, seen map[copyKey]reflect.Value) reflect.Value {
+	cp := reflect.New(v.Type()).Elem()
+	cp.Set(v)
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			break
+		}
+		key := copyKey{v.Pointer(), v.Type()}
+		if p, ok := seen[key]; ok {
+			cp.Set(p)
+			break
+		}
+		p := reflect.New(v.Type().Elem())
+		seen[key] = p
+		p.Elem().Set(deepCopy(v.Elem(), seen))
+		cp.Set(p)
+	case reflect.Slice:
+		if v.IsNil() {
+			break
+		}
+		s := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			s.Index(i).Set(deepCopy(v.Index(i), seen))
+		}
+		cp.Set(s)
+	case reflect.Map:
+		if v.IsNil() {
+			break
+		}
+		m := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			m.SetMapIndex(iter.Key(), deepCopy(iter.Value(), seen))
+		}
+		cp.Set(m)
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			cp.Index(i).Set(deepCopy(v.Index(i), seen))
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if cp.Field(i).CanSet() {
+				cp.Field(i).Set(deepCopy(v.Field(i), seen))
+			}
+		}
+	}
+	return cp
+}
+
+// contains 报告 names 中是否包含 name。
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+package structflag
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestDefaultOfAnonymousStruct(t *testing.T) {
+	v := &struct {
+		Timeout time.Duration `flag:"timeout" default:"5s"`
+		DB      struct {
+			Max int `flag:"max" default:"10"`
+		} `flag:"db"`
+	}{}
+	got, ok := DefaultOf(v, "timeout")
+	if !ok || got != 5*time.Second {
+		t.Errorf("DefaultOf(timeout) = %v, %v; want 5s, true", got, ok)
+	}
+	got, ok = DefaultOf(v, "db-max")
+	if !ok || got != 10 {
+		t.Errorf("DefaultOf(db-max) = %v, %v; want 10, true", got, ok)
+	}
+
+	if env := ExportEnv(v, "APP"); len(env) != 2 || env[0] != "APP_TIMEOUT=0s" || env[1] != "APP_DB_MAX=0" {
+		t.Errorf("ExportEnv = %q", env)
+	}
+	var buf bytes.Buffer
+	if err := WriteSample(&buf, v, FormatJSON); err != nil {
+		t.Fatalf("WriteSample: %v", err)
+	}
+}
+
+type copyConfig struct {
+	Tags   []string          `flag:"tags" default:"a,b"`
+	Labels map[string]string `flag:"-"`
+	Port   *int              `flag:"port"`
+}
+
+func (c *copyConfig) SetDefault() {
+	c.Tags[0] = "computed"
+	c.Labels["env"] = "computed"
+	*c.Port = 8080
+}
+
+func TestLoadCopyLeavesValueUnchanged(t *testing.T) {
+	port := 3
+	v := &copyConfig{
+		Tags:   []string{"x"},
+		Labels: map[string]string{"env": "prod"},
+		Port:   &port,
+	}
+	check := func(fn string) {
+		t.Helper()
+		if v.Tags[0] != "x" || v.Labels["env"] != "prod" || port != 3 {
+			t.Errorf("%s modified v: Tags = %q, Labels = %v, *Port = %d", fn, v.Tags, v.Labels, port)
+		}
+	}
+	if _, err := Schema("", v); err != nil {
+		t.Fatalf("Schema: %v", err)
+	}
+	check("Schema")
+	if got, ok := DefaultOf(v, "port"); !ok || *got.(*int) != 8080 {
+		t.Errorf("DefaultOf(port) = %v, %v; want a pointer to 8080, true", got, ok)
+	}
+	check("DefaultOf")
+	var buf bytes.Buffer
+	if err := WriteSample(&buf, v, FormatJSON); err != nil {
+		t.Fatalf("WriteSample: %v", err)
+	}
+	check("WriteSample")
+}
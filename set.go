@@ -0,0 +1,231 @@
+package structflag
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// WhichSet 报告 v 的每个字段对应的标志是否在命令行中被显式设置。
+//
+// 返回的映射以标志的完整名称（包含前缀）为键，包含 v 生成的所有标志。
+// 由于短选项与长名称共享同一个字段，只要设置了其中之一，该字段对应的长名称即为 true。
+//
+// WhichSet 应在 fs.Parse 之后调用，prefix 和 opts 应与加载 v 时使用的相同。
+// WhichSet 不会修改 fs 或 v。如果 v 不是指向结构体的非 nil 指针，则会引发 panic。
+func WhichSet(fs *flag.FlagSet, prefix string, v interface{}, opts ...Option) map[string]bool {
+	l := newLoader("WhichSet", fs, opts)
+	if err := l.collect(prefix, v); err != nil {
+		panic(err)
+	}
+	if err := l.err(); err != nil {
+		panic(err)
+	}
+
+	set := make(map[string]bool, len(l.fields))
+	byName := make(map[string]string, len(l.fields))
+	byAddr := make(map[uintptr]string, len(l.fields))
+	for _, f := range l.fields {
+		set[f.name] = false
+		byAddr[f.addr] = f.name
+		for _, name := range f.names() {
+			byName[name] = f.name
+		}
+	}
+	fs.Visit(func(fl *flag.Flag) {
+		// 加载时注册的名称（包括 AutoShort 分配的短选项）记录在 registry 中。
+		if f := lookup(fs, fl.Name); f != nil {
+			if name, ok := byAddr[f.addr]; ok {
+				set[name] = true
+				return
+			}
+		}
+		if name, ok := byName[fl.Name]; ok {
+			set[name] = true
+		}
+	})
+	return set
+}
+
+// CheckMutex 检查互斥组中是否有多于一个标志在命令行中被设置。
+//
+// 互斥组通过 "mutex" 标签声明，具有相同组名的字段互斥，例如：
+//
+//	Quiet   bool `flag:"quiet" mutex:"verbosity"`
+//	Verbose bool `flag:"verbose" mutex:"verbosity"`
+//
+// CheckMutex 应在 fs.Parse 之后调用，v 必须是此前通过 LoadTo 加载到 fs 上的结构体指针。
+// 如果存在冲突，返回的错误会列出同一组中所有被设置的标志。
+func CheckMutex(fs *flag.FlagSet, v interface{}) error {
+	fields := fieldsOf(fs, v)
+	set := make(map[*field]bool)
+	fs.Visit(func(fl *flag.Flag) {
+		if f := lookup(fs, fl.Name); f != nil && f.root == v {
+			set[f] = true
+		}
+	})
+
+	var groups []string
+	members := make(map[string][]string)
+	for _, f := range fields {
+		if f.mutex == "" || !set[f] {
+			continue
+		}
+		if _, ok := members[f.mutex]; !ok {
+			groups = append(groups, f.mutex)
+		}
+		members[f.mutex] = append(members[f.mutex], "-"+f.name)
+	}
+	for _, g := range groups {
+		if names := members[g]; len(names) > 1 {
+			return fmt.Errorf("structflag: flags %s are mutually exclusive (group %q)", strings.Join(names, ", "), g)
+		}
+	}
+	return nil
+}
+
+// CheckRequired 检查 v 中所有必需的标志是否都在命令行中被设置。
+//
+// 必需的标志通过 "flag" 标签的 "required" 选项声明，例如：
+//
+//	Addr string `flag:"addr,required"`
+//
+// 带有 "required-unless" 标签的字段只在其中列出的标志都没有设置时才是必需的，例如 -config 在设置了 -stdin 时可以省略：
+//
+//	Config string `flag:"config" required-unless:"stdin"`
+//	Stdin  bool   `flag:"stdin"`
+//
+// 标签中以逗号分隔的名称与别名一样加上所在结构体的前缀，可以是 fs 上的任何标志；
+// 由 structflag 注册的标志通过其任一名称（包括短选项和别名）设置均可。
+//
+// CheckRequired 应在 fs.Parse 之后调用，v 必须是此前通过 LoadTo 加载到 fs 上的结构体指针。
+// 返回的错误会列出所有未设置的必需标志；有多个错误时使用 errors.Join 合并。
+func CheckRequired(fs *flag.FlagSet, v interface{}) error {
+	set := make(map[*field]bool)
+	visited := make(map[string]bool)
+	fs.Visit(func(fl *flag.Flag) {
+		visited[fl.Name] = true
+		if f := lookup(fs, fl.Name); f != nil {
+			set[f] = true
+		}
+	})
+	isSet := func(name string) bool {
+		if f := lookup(fs, name); f != nil {
+			return set[f]
+		}
+		return visited[name]
+	}
+
+	var missing []string
+	var errs []error
+	for _, f := range fieldsOf(fs, v) {
+		if f.required && !set[f] {
+			missing = append(missing, "-"+f.name)
+		}
+		if len(f.unless) == 0 || set[f] {
+			continue
+		}
+		satisfied := false
+		for _, name := range f.unless {
+			if fs.Lookup(name) == nil {
+				errs = append(errs, fmt.Errorf("structflag: required-unless tag on field %s refers to undefined flag -%s", f.path, name))
+			}
+			satisfied = satisfied || isSet(name)
+		}
+		if !satisfied {
+			errs = append(errs, fmt.Errorf("structflag: flag -%s is required unless -%s is set", f.name, strings.Join(f.unless, " or -")))
+		}
+	}
+	switch len(missing) {
+	case 0:
+	case 1:
+		errs = append([]error{fmt.Errorf("structflag: required flag %s is not set", missing[0])}, errs...)
+	default:
+		errs = append([]error{fmt.Errorf("structflag: required flags %s are not set", strings.Join(missing, ", "))}, errs...)
+	}
+	return joinErrors(errs)
+}
+
+// BindArgs 将 fs 解析后剩余的位置参数（即 fs.Args()）复制到 v 中带有 `flag:"..."` 标签的字段，例如：
+//
+//	Files []string `flag:"..."`
+//
+// 该字段的类型必须为 []string，并且 v 中最多只能有一个这样的字段，否则返回错误。
+// 加载 v 时该字段不会生成标志。如果 v 中没有这样的字段，BindArgs 不做任何事情。
+// BindArgs 应在 fs.Parse 之后调用。
+func BindArgs(fs *flag.FlagSet, v interface{}) error {
+	l := newLoader("BindArgs", fs, nil)
+	if err := l.collect("", v); err != nil {
+		return err
+	}
+	if err := l.err(); err != nil {
+		return err
+	}
+	if l.args.IsValid() {
+		args := append([]string(nil), fs.Args()...)
+		l.args.Set(reflect.ValueOf(args).Convert(l.args.Type()))
+	}
+	return nil
+}
+
+// Reset 将 v 的每个字段恢复为加载时应用的默认值，即对应标志的 DefValue。
+//
+// v 必须是此前通过 LoadTo 加载到 fs 上的结构体指针。切片字段会恢复为完整的默认列表，
+// 指针字段和没有默认实现的接口字段在默认值为空时恢复为 nil。已弃用的标志不会打印警告。
+// 某个字段无法恢复时 Reset 仍会恢复其余字段，并报告所有错误；有多个错误时使用 errors.Join 合并。
+func Reset(fs *flag.FlagSet, v interface{}) error {
+	var errs []error
+	for _, f := range fieldsOf(fs, v) {
+		fl := fs.Lookup(f.name)
+		if fl == nil {
+			continue
+		}
+		value := unwrapFlag(fl).Value
+		set := value.Set
+		if ds, ok := value.(defaultSetter); ok {
+			set = ds.SetDefault
+		}
+		if err := set(fl.DefValue); err != nil {
+			errs = append(errs, fmt.Errorf("structflag: reset field %s to %q: %v", f.path, fl.DefValue, err))
+		}
+	}
+	return joinErrors(errs)
+}
+
+// NormalizeArgs 返回 args 的副本，其中由 WithCaseInsensitive 加载的标志的名称被转换为小写，
+// 例如 "-Port" 变为 "-port"，"--Port=80" 变为 "--port=80"。标志的值、未知的标志、
+// "--" 之后的参数以及第一个非标志参数之后的参数（flag 包将其视为位置参数）保持不变。
+// NormalizeArgs 应在 fs.Parse 之前调用：
+//
+//	fs.Parse(structflag.NormalizeArgs(fs, os.Args[1:]))
+func NormalizeArgs(fs *flag.FlagSet, args []string) []string {
+	out := append([]string(nil), args...)
+	for i := 0; i < len(out); i++ {
+		arg := out[i]
+		if arg == "--" || len(arg) < 2 || arg[0] != '-' {
+			break
+		}
+		dashes := "-"
+		if arg[1] == '-' {
+			dashes = "--"
+		}
+		name, value := arg[len(dashes):], ""
+		hasValue := false
+		if j := strings.IndexByte(name, '='); j >= 0 {
+			name, value, hasValue = name[:j], name[j:], true
+		}
+		lower := strings.ToLower(name)
+		if f := lookup(fs, lower); f != nil && f.foldCase && lower != f.short {
+			name = lower
+			out[i] = dashes + name + value
+		}
+		// 非布尔标志的值作为下一个参数给出时跳过该参数。
+		if fl := fs.Lookup(name); fl != nil && !hasValue {
+			if b, ok := fl.Value.(interface{ IsBoolFlag() bool }); !ok || !b.IsBoolFlag() {
+				i++
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,105 @@
+package structflag
+
+import (
+	"bytes"
+	"flag"
+	"reflect"
+	"testing"
+)
+
+type resetStore interface{ Name() string }
+
+type resetMemory struct{}
+
+func (resetMemory) Name() string { return "memory" }
+
+func TestReset(t *testing.T) {
+	RegisterFactory(map[string]func() resetStore{"memory": func() resetStore { return resetMemory{} }})
+	defer RegisterFactory(map[string]func() resetStore{})
+
+	var cfg struct {
+		Store resetStore `flag:"store"`
+		Tags  []string   `flag:"tag" default:"a,b"`
+		Old   int        `flag:"old" default:"1" deprecated:"use -new"`
+	}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	var out bytes.Buffer
+	fs.SetOutput(&out)
+	if err := TryLoadTo(fs, "", &cfg); err != nil {
+		t.Fatalf("TryLoadTo: %v", err)
+	}
+	if err := fs.Parse([]string{"-store", "memory", "-tag", "x", "-old", "2"}); err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	out.Reset()
+
+	if err := Reset(fs, &cfg); err != nil {
+		t.Fatalf("Reset: %v", err)
+	}
+	if cfg.Store != nil {
+		t.Errorf("Store = %v, want nil", cfg.Store)
+	}
+	if !reflect.DeepEqual(cfg.Tags, []string{"a", "b"}) {
+		t.Errorf("Tags = %q, want [a b]", cfg.Tags)
+	}
+	if cfg.Old != 1 {
+		t.Errorf("Old = %d, want 1", cfg.Old)
+	}
+	if out.Len() > 0 {
+		t.Errorf("Reset printed %q, want no output", out.String())
+	}
+}
+
+type resetConfig struct {
+	A    int      `flag:"a"`
+	Tags []string `flag:"tag" default:"a"`
+	B    int      `flag:"b"`
+}
+
+func TestResetReportsAllErrors(t *testing.T) {
+	var cfg resetConfig
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	if err := TryLoadTo(fs, "", &cfg); err != nil {
+		t.Fatalf("TryLoadTo: %v", err)
+	}
+	if err := fs.Parse([]string{"-tag", "x", "-a", "1", "-b", "2"}); err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	fs.Lookup("a").DefValue = "bad"
+	fs.Lookup("b").DefValue = "bad"
+
+	err := Reset(fs, &cfg)
+	if err == nil {
+		t.Fatal("Reset succeeded, want an error")
+	}
+	want := "structflag: reset field resetConfig.A to \"bad\": parse error\n" +
+		"structflag: reset field resetConfig.B to \"bad\": parse error"
+	if err.Error() != want {
+		t.Errorf("Reset error = %q, want %q", err, want)
+	}
+	if !reflect.DeepEqual(cfg.Tags, []string{"a"}) {
+		t.Errorf("Tags = %q, want [a]", cfg.Tags)
+	}
+}
+
+func TestWhichSetAutoShort(t *testing.T) {
+	var cfg struct {
+		Port int
+		Host string
+	}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	if err := TryLoadTo(fs, "", &cfg, AutoShort()); err != nil {
+		t.Fatalf("TryLoadTo: %v", err)
+	}
+	if fs.Lookup("P") == nil {
+		t.Fatal("AutoShort did not assign -P to Port")
+	}
+	if err := fs.Parse([]string{"-P", "80"}); err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	got := WhichSet(fs, "", &cfg, AutoShort())
+	want := map[string]bool{"Port": true, "Host": false}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("WhichSet = %v, want %v", got, want)
+	}
+}
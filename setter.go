@@ -0,0 +1,47 @@
+package structflag
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+	"unicode"
+	"unicode/utf8"
+)
+
+var setterType = reflect.TypeOf(func(string) error { return nil })
+
+// setterFor 返回使用 WithSetters 时为未导出字段 sf 生成标志的 flag.Value。val 为包含该字段的结构体。
+// 如果结构体的指针没有名为 "Set" 加上首字母大写的字段名称、签名为 func(string) error 的导出方法，则返回 nil。
+func setterFor(val reflect.Value, sf reflect.StructField) flag.Value {
+	if !val.CanAddr() {
+		return nil
+	}
+	r, size := utf8.DecodeRuneInString(sf.Name)
+	m := val.Addr().MethodByName("Set" + string(unicode.ToUpper(r)) + sf.Name[size:])
+	if !m.IsValid() || !m.CanInterface() || m.Type() != setterType {
+		return nil
+	}
+	return &setterValue{field: val.FieldByIndex(sf.Index), set: m.Interface().(func(string) error)}
+}
+
+// setterValue 通过结构体的 setter 方法设置未导出的字段。
+type setterValue struct {
+	field reflect.Value
+	set   func(string) error
+}
+
+func (v *setterValue) Set(s string) error {
+	return v.set(s)
+}
+
+// String 返回字段的当前值。未导出字段无法通过 Interface 读取，因此由 fmt 格式化其 reflect.Value。
+func (v *setterValue) String() string {
+	if v == nil || !v.field.IsValid() {
+		return ""
+	}
+	return fmt.Sprint(v.field)
+}
+
+func (v *setterValue) IsBoolFlag() bool {
+	return v.field.IsValid() && v.field.Kind() == reflect.Bool
+}
@@ -0,0 +1,44 @@
+package structflag
+
+// SkipReason 表示字段没有生成标志的原因。
+type SkipReason int
+
+const (
+	// SkipIgnored 表示字段带有 `flag:"-"` 标签。
+	SkipIgnored SkipReason = iota + 1
+	// SkipUnexported 表示字段未导出。
+	SkipUnexported
+	// SkipUnsupportedKind 表示字段的类型不受支持。
+	SkipUnsupportedKind
+	// SkipEmbedded 表示字段是被 WithSkipEmbeddedNonStruct 跳过的非结构体嵌入字段。
+	SkipEmbedded
+	// SkipConflict 表示字段的标志名称已在 FlagSet 中定义，并且设置了 OnConflict(ConflictSkip)。
+	SkipConflict
+)
+
+func (r SkipReason) String() string {
+	switch r {
+	case SkipIgnored:
+		return "ignored"
+	case SkipUnexported:
+		return "unexported"
+	case SkipUnsupportedKind:
+		return "unsupported kind"
+	case SkipEmbedded:
+		return "embedded non-struct"
+	case SkipConflict:
+		return "conflict"
+	}
+	return "unknown"
+}
+
+// Skipped 记录一个没有生成标志的字段。
+type Skipped struct {
+	FieldPath string // Go 字段路径，例如 "Config.Server.Port"
+	Reason    SkipReason
+}
+
+// skip 记录字段 path 因 reason 被跳过。
+func (l *loader) skip(path string, reason SkipReason) {
+	l.skipped = append(l.skipped, Skipped{FieldPath: path, Reason: reason})
+}
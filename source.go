@@ -0,0 +1,83 @@
+package structflag
+
+import "flag"
+
+// SourceKind 表示字段的值的来源。
+type SourceKind int
+
+const (
+	// SourceInitial 表示字段保持加载时的值，包括 Defaulter 计算的值。
+	SourceInitial SourceKind = iota
+	// SourceDefault 表示值来自 "default" 标签。
+	SourceDefault
+	// SourceFile 表示值来自 ParseWithConfig 或 Resolve 读取的配置文件。
+	SourceFile
+	// SourceEnv 表示值来自 ApplyEnv 读取的环境变量。
+	SourceEnv
+	// SourceFlag 表示值来自命令行中的标志。
+	SourceFlag
+)
+
+func (k SourceKind) String() string {
+	switch k {
+	case SourceInitial:
+		return "initial value"
+	case SourceDefault:
+		return "default tag"
+	case SourceFile:
+		return "config file"
+	case SourceEnv:
+		return "environment"
+	case SourceFlag:
+		return "command line"
+	}
+	return "unknown"
+}
+
+// Source 记录字段的值来自何处。
+type Source struct {
+	Kind SourceKind
+	// Name 为具体的来源：命令行中使用的标志名称、环境变量的名称，或者以 ":" 连接的配置文件路径和键路径，
+	// 例如 "config.json:db.max-conns"。SourceInitial 和 SourceDefault 的 Name 为空。
+	Name string
+}
+
+func (s Source) String() string {
+	switch {
+	case s.Name == "":
+		return s.Kind.String()
+	case s.Kind == SourceFlag:
+		return s.Kind.String() + " -" + s.Name
+	}
+	return s.Kind.String() + " " + s.Name
+}
+
+// ValueSources 返回 v 的每个标志的值的来源，以标志的完整名称（包含前缀）为键。
+//
+// 来源按照与 Resolve 相同的优先级确定：命令行中显式设置的标志总是被报告为 SourceFlag，
+// 即使其值与默认值相同；其次是 ApplyEnv 应用的环境变量和 ParseWithConfig 或 Resolve 读取的配置文件，
+// 最后是 "default" 标签和字段加载时的值。LoadTo 的 WithFile 选项读取的值被报告为 SourceFile，
+// 而在 LoadTo 之前通过 LoadFile 等函数设置的值与其他初始值一样被报告为 SourceInitial。
+//
+// ValueSources 应在 fs.Parse 之后调用，v 必须是此前通过 LoadTo 加载到 fs 上的结构体指针。
+func ValueSources(fs *flag.FlagSet, v interface{}) map[string]Source {
+	sources := make(map[string]Source)
+	for f, s := range fieldSources(fs, v) {
+		sources[f.name] = s
+	}
+	return sources
+}
+
+// fieldSources 返回 fs 上由 v 生成的每个字段的值的来源。
+func fieldSources(fs *flag.FlagSet, v interface{}) map[*field]Source {
+	sources := make(map[*field]Source)
+	for _, f := range fieldsOf(fs, v) {
+		sources[f] = f.source
+	}
+	fs.Visit(func(fl *flag.Flag) {
+		if f := lookup(fs, fl.Name); f != nil && f.root == v {
+			sources[f] = Source{Kind: SourceFlag, Name: fl.Name}
+		}
+	})
+	return sources
+}
@@ -0,0 +1,58 @@
+package structflag
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestValueSourcesFile(t *testing.T) {
+	type config struct {
+		Host string `flag:"host"`
+		Port int    `flag:"port" default:"8080"`
+		Name string `flag:"name"`
+	}
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"host": "db1"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var before config
+	if err := LoadFile(path, &before); err != nil {
+		t.Fatalf("LoadFile: %v", err)
+	}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	if err := TryLoadTo(fs, "", &before); err != nil {
+		t.Fatalf("TryLoadTo: %v", err)
+	}
+	if err := fs.Parse(nil); err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	want := map[string]Source{
+		"host": {Kind: SourceInitial},
+		"port": {Kind: SourceDefault},
+		"name": {Kind: SourceInitial},
+	}
+	if got := ValueSources(fs, &before); !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadFile before LoadTo: got %v, want %v", got, want)
+	}
+
+	var with config
+	fs = flag.NewFlagSet("test", flag.ContinueOnError)
+	if err := TryLoadTo(fs, "", &with, WithFile(path)); err != nil {
+		t.Fatalf("TryLoadTo: %v", err)
+	}
+	if err := fs.Parse([]string{"-name", "x"}); err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	want = map[string]Source{
+		"host": {Kind: SourceFile, Name: path + ":host"},
+		"port": {Kind: SourceDefault},
+		"name": {Kind: SourceFlag, Name: "name"},
+	}
+	if got := ValueSources(fs, &with); !reflect.DeepEqual(got, want) {
+		t.Errorf("WithFile: got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,119 @@
+package structflag
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// checkTags 在严格模式下检查字段 sf 的标签键。
+//
+// 如果设置了 WithAllowedTags，则所有不在 structflag 标签和允许列表中的键都会报告错误；
+// 否则只报告与 structflag 标签拼写相近的未知键（例如 "usge"），json、yaml 等无关的键不受影响。
+func (l *loader) checkTags(path string, sf reflect.StructField) error {
+	if !l.opts.strictTags {
+		return nil
+	}
+	for _, key := range tagKeysOf(sf.Tag) {
+		if isTagKey(key) {
+			continue
+		}
+		if l.opts.allowedTags != nil {
+			if !l.opts.allowedTags[key] {
+				return fmt.Errorf("structflag: unknown tag %q on field %s", key, path)
+			}
+			continue
+		}
+		for _, known := range tagKeys {
+			if d := editDistance(key, known); d <= 2 && d*2 < len(known) {
+				return fmt.Errorf("structflag: unknown tag %q on field %s, did you mean %q?", key, path, known)
+			}
+		}
+	}
+	return nil
+}
+
+// isTagKey 报告 key 是否为 structflag 识别的标签。
+func isTagKey(key string) bool {
+	for _, k := range tagKeys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// tagKeysOf 按照 reflect.StructTag 的约定解析 tag，返回其中所有的键。
+func tagKeysOf(tag reflect.StructTag) []string {
+	var keys []string
+	for tag != "" {
+		// 跳过前导空格。
+		i := 0
+		for i < len(tag) && tag[i] == ' ' {
+			i++
+		}
+		tag = tag[i:]
+		if tag == "" {
+			break
+		}
+
+		// 扫描到冒号为止。空格、引号或控制字符都表示语法错误。
+		i = 0
+		for i < len(tag) && tag[i] > ' ' && tag[i] != ':' && tag[i] != '"' && tag[i] != 0x7f {
+			i++
+		}
+		if i == 0 || i+1 >= len(tag) || tag[i] != ':' || tag[i+1] != '"' {
+			break
+		}
+		key := string(tag[:i])
+		tag = tag[i+1:]
+
+		// 扫描带引号的字符串以找到值的结尾。
+		i = 1
+		for i < len(tag) && tag[i] != '"' {
+			if tag[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= len(tag) {
+			break
+		}
+		if _, err := strconv.Unquote(string(tag[:i+1])); err != nil {
+			break
+		}
+		keys = append(keys, key)
+		tag = tag[i+1:]
+	}
+	return keys
+}
+
+// editDistance 返回 a 和 b 之间的 Levenshtein 距离。
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = minInt(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func minInt(x int, ys ...int) int {
+	for _, y := range ys {
+		if y < x {
+			x = y
+		}
+	}
+	return x
+}
@@ -2,18 +2,36 @@
 package structflag
 
 import (
+	"errors"
 	"flag"
+	"fmt"
+	"os"
+	"path"
 	"reflect"
 	"strconv"
+	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 )
 
 // Load 为结构体的每个字段创建一个命令行标志。详见 LoadTo 有关如何配置标志命名、用法和默认值的详细说明。
 //
 // 这些标志将创建在 flag.CommandLine 上，这是默认（全局）的 FlagSet。标志名称不带前缀。
-func Load(v interface{}) {
-	LoadTo(flag.CommandLine, "", v)
-	flag.Parse()
+func Load(v interface{}, opts ...Option) {
+	l := newLoader("Load", flag.CommandLine, opts)
+	if err := l.loadAll("", v); err != nil {
+		panic(err)
+	}
+	if err := ApplyEnv(flag.CommandLine); err != nil {
+		panic(err)
+	}
+	args, err := parseArgs(flag.CommandLine, l.opts, os.Args[1:])
+	if err != nil {
+		panic(err)
+	}
+	// flag.CommandLine 的错误处理方式为 ExitOnError，与 flag.Parse 相同。
+	_ = flag.CommandLine.Parse(args)
 }
 
 // LoadTo 为给定 FlagSet 的结构体的每个字段创建一个命令行标志。
@@ -23,12 +41,22 @@ func Load(v interface{}) {
 //
 // 创建的标志将设置为更新 v 的字段；调用 fs.Parse 后，v 的字段可能被 flag 包更新。
 //
-// v 的字段值将作为传递给 flag 包的默认值。
+// v 的字段值将作为传递给 flag 包的默认值，除非字段设置了 "default" 标签。
+// 实现了 Defaulter 的字段和结构体会在注册之前调用其 SetDefault 方法计算默认值，"default" 标签仍然优先。
+// "default" 标签中的 $NAME、${NAME} 和 ${NAME:-fallback} 在加载时使用环境变量展开，"$$" 表示字面的 "$"，
+// 例如 `default:"${HOME}/.cache/myapp"` 或 `default:"$TMPDIR/work"`；引用的环境变量未设置且没有 fallback 时报告错误。
+// 对所有类型的字段，没有 "default" 标签与 `default:""` 是不同的：前者保留字段的当前值作为默认值，
+// 后者显式地将默认值设为空，例如空字符串、空切片或 nil 指针；对于空字符串不是有效值的类型则报告错误。
 //
 // 默认情况下，标志将按照给定结构体中的字段名称命名。要设置自定义名称，请使用名为 "flag" 的标签。
-// 要禁用某个字段生成任何标志，请使用名称 "-"。
+// 要禁用某个字段生成任何标志，请使用名称 "-"。与 encoding/json 相同，`flag:"-,"`（带有逗号）
+// 表示标志名称就是 "-" 而不是跳过该字段。由于标志名称不能以 "-" 开头，这只在加上前缀时有效，
+// 例如前缀为 "x" 时得到标志 "x--"；没有前缀时会报告无效的标志名称。
 //
 // 默认情况下，标志不会有任何用法信息。要设置用法信息，请使用名为 "usage" 的标签。
+// 用法信息中的 {default} 会被替换为标志实际的默认值，{env} 会被替换为 "env" 标签指定的环境变量，
+// {choices} 会被替换为以逗号分隔的可选值（例如通过 RegisterFactory 注册的名称），"{{" 表示字面的 "{"，例如 `usage:"listen address (default {default})"`。
+// 其他占位符原样保留；设置了 WithStrictTags 时则报告错误。
 //
 // 结构体字段标签及其含义的示例：
 //
@@ -44,6 +72,9 @@ func Load(v interface{}) {
 //	// Field 将被此包忽略。
 //	Field int `flag:"-"`
 //
+//	// Field 会作为一个名为 "-" 的标志出现（需要加上前缀）。
+//	Field int `flag:"-,"`
+//
 // 此包支持以下字段类型，其他类型将被忽略：
 //
 //	bool
@@ -54,9 +85,49 @@ func Load(v interface{}) {
 //	uint64
 //	time.Duration
 //
-// 这些类型对应于 flag 包原生支持的类型。
+// 这些类型对应于 flag 包原生支持的类型。此外还支持以下类型：
+//
+//	int8, int16, int32, uint8, uint16, uint32, float32 以及底层类型为基本类型的具名类型
+//	url.URL, *url.URL      使用 url.Parse 解析
+//	big.Int, *big.Int      使用 big.Int.SetString 解析，支持 0x 等前缀
+//	big.Float, *big.Float  使用 big.Float.SetString 解析，沿用字段原有的精度
+//	time.Time              使用 "layout" 标签指定的格式解析，默认为 time.RFC3339
+//
+// time.Time 字段的 "default" 标签还可以是 "now" 或 "now-1h"、"now+30m" 等相对于加载时刻的表达式，
+// 以及 time.RFC3339、time.DateTime 或 time.DateOnly 格式的绝对时间，例如：
+//
+//	StartAt time.Time `flag:"start-at" layout:"2006-01-02 15:04" default:"now-1h"`
+//
+// 以上所有基本类型的指针形式（例如 *int、*time.Duration）同样受支持，所有标签的行为与非指针形式相同。
+// 指针形式的字段会在设置时分配新值；没有 "default" 标签且未设置的指针字段保持为 nil，
+// 因此可以区分未设置的标志与显式设置的零值。
+//
+// 元素为基本类型（包括 time.Duration 以及各种长度的整数和浮点数）的切片字段也受支持，
+// 每次在命令行中设置该标志都会追加一个元素，例如 "-backoff 1s -backoff 5s"。
+// 切片字段的 "default" 标签使用逗号分隔多个元素，例如 `default:"1s,5s,30s"`；
+// 命令行中第一次设置该标志时会替换默认值，而不是追加到默认值之后。
+//
+// 元素为基本类型的数组字段（例如 [3]uint8）使用以逗号或空白分隔的元素列表设置，
+// 例如 "-rgb 255,128,0"，元素个数必须与数组的长度相同。
+//
+// 其他类型可以通过 RegisterParser 注册解析函数。接口类型的字段可以通过 RegisterFactory
+// 注册一组按名称选择的实现，例如 "-store redis"。
 //
 // 如果字段的值是一个结构体，则该嵌套结构体将递归加载。匿名结构体字段将按照其类型的名称加载，除非通过 "flag" 标签重命名。
+// 嵌套结构体字段上的 "default" 和 "short" 标签没有意义，会被报告为错误。
+//
+// 在 "flag" 标签中使用 "inline" 或 "squash" 选项（例如 `flag:",inline"`）可以使嵌套结构体不增加前缀，
+// 其字段如同直接定义在外层结构体中，这同样适用于嵌入的结构体。由此产生的名称冲突会同时报告两个字段的路径。
+// 嵌套结构体字段上的 `noprefix:"true"` 标签具有相同的效果，并且不受 "flag" 标签中的名称影响，
+// 因此可以在同一个结构体中混合加前缀和不加前缀的嵌套结构体。
+//
+// 非结构体类型的嵌入字段（例如嵌入的 `type Count int`）与普通字段一样生成标志，
+// 其名称与嵌入结构体一样取自类型名称（此例中为 "Count"），除非通过 "flag" 标签重命名。
+// 使用 WithSkipEmbeddedNonStruct 选项可以跳过这些字段。
+//
+// 泛型结构体的实例化（例如 Config[int]）与普通结构体一样加载。嵌入的泛型结构体按照不含类型参数的
+// 名称（此例中为 "Config"）加载；类型参数为不支持的类型时，对应字段与其他不支持的字段一样被跳过，
+// 并在 LoadResult 中以 SkipUnsupportedKind 报告。
 //
 // 例如，给定以下 "config" 结构体：
 //
@@ -79,105 +150,1309 @@ func Load(v interface{}) {
 //	embezzled-quux
 //
 // LoadTo 遵循 Go 的常规可见性规则。如果字段未导出，则不会为此字段创建标志。
+// 如果未导出的字段带有 structflag 的标签（例如 "flag" 或 "default"），则会报告错误，
+// 因为这些标签不会产生任何效果，几乎总是一个疏忽。
+// 使用 LoadResult 可以获得所有没有生成标志的字段及其原因。
+//
+// 如果结构体类型（间接地）包含其自身，包括通过指向自身的指针字段（例如 Self *Config），
+// 则会在检测到循环的字段处报告错误，而不会导致栈溢出。其他指向结构体的指针字段不会被加载。
+// 可以通过 WithMaxDepth 限制嵌套结构体的最大深度。
+//
+// 如果 v 不是指向结构体的非 nil 指针，则会引发 panic，错误信息中会说明实际传入的值，例如：
+//
+//	structflag: LoadTo requires a non-nil pointer to a struct, got main.Config
 //
-// 循环数据结构会导致栈溢出。
+// 对同一个 FlagSet 重复加载同一个结构体是安全的：此前已由 structflag 为同一字段注册的标志会被跳过。
 //
-// 如果 v 不是指向结构体的指针，则会引发 panic。
+// 同一个结构体也可以加载到多个 FlagSet 上，例如多个子命令共享的公共配置。每个 FlagSet 上的标志都直接设置
+// 同一个结构体的字段，因此无论哪个 FlagSet 解析命令行，结果都保存在该结构体中。切片字段在每个 FlagSet 上
+// 独立地累积：在某个 FlagSet 上第一次设置时替换字段中原有的元素，而不是追加到另一个 FlagSet 设置的元素之后；
+// 计数标志则在字段的当前值上继续累加。由于加载时会应用 "default" 标签和 Defaulter，
+// 应在解析任何一个 FlagSet 之前加载所有 FlagSet，以免后续的加载覆盖已解析的值。
+//
+// 如果两个字段会生成同名的标志（包括长名称与长名称、短选项与短选项、短选项与长名称之间的冲突），
+// 或者标志名称已在 fs 中定义，则会引发 panic，错误信息中包含冲突双方的字段路径。
+// 如需以 error 的形式获得这些错误，请使用 TryLoadTo。
+// 使用 WithDropConflictingShorts 选项时，与其他标志冲突的短选项将被丢弃而不是报告错误。
 //
 // 新增特性：
 //   - 支持设置短选项(short option)，可以通过 "short" 标签指定。例如：
-//     Field int `flag:"foo" short:"-f"`
+//     Field int `flag:"foo" short:"f"`
+//     短选项前导的破折号会被去掉，因此 `short:"-f"` 与 `short:"f"` 等价。
+//     短选项必须恰好为一个字符，且不能与长名称相同。短选项不会加上前缀。
+//     使用 PrintDefaults 打印用法信息时，短选项会与长名称显示在同一行。
+//     使用 WithUsage 选项时，LoadTo 会将 fs 的用法函数替换为使用 PrintDefaults 的版本。
+//     同时带有 "short" 标签的 `flag:"-"` 字段只注册短选项而没有长名称，其他标签照常生效。例如：
+//     Verbose bool `flag:"-" short:"v" usage:"be loud"`
 //   - 支持设置默认值，默认值可以通过 "default" 标签指定。例如：
 //     Field int `flag:"foo" default:"42"`
-func LoadTo(fs *flag.FlagSet, prefix string, v interface{}) {
-	val := reflect.ValueOf(v).Elem()
-	load(fs, prefix, val)
+//     整数类型字段的默认值无法解析或超出字段类型的范围时会报告错误，整数默认值总是按照十进制解析。
+//     布尔类型字段的默认值按照 strconv.ParseBool 解析，无效的值同样会报告错误。
+//   - 支持在用法信息中隐藏默认值，适用于令牌等敏感字段。`show-default:"false"` 不显示默认值，
+//     "mask" 标签则以给定的占位符代替默认值显示。这只影响 PrintDefaults 和 PrintGrouped 的输出，
+//     不影响字段实际使用的值。例如：
+//     Token string `flag:"token" mask:"****"`
+//   - 支持互斥组，可以通过 "mutex" 标签指定组名，解析后使用 CheckMutex 检查。例如：
+//     Quiet bool `flag:"quiet" mutex:"verbosity"`
+//   - 支持在 "flag" 标签的名称之后以逗号分隔的选项，类似于 encoding/json。支持的选项有
+//     short=x、usage=xxx、default=xxx、required、inline 和 squash；其中 short、usage 和 default
+//     优先于同名的单独标签。名称为空时（例如 `flag:",required"`）仍使用由字段名称得到的名称。
+//     选项的值中的逗号可以使用反斜杠转义，或者将值用单引号括起来。严格模式下未知的选项会报告错误。
+//     required 选项声明的必需标志可以在解析后使用 CheckRequired 检查。例如：
+//     Verbose bool `flag:"verbose,short=v,usage='be loud, really'"`
+//   - 支持通过 "alias" 标签为标志指定以逗号分隔的额外长名称，例如重命名后仍需兼容的旧名称。
+//     别名与字段自身的名称一样加上所在结构体的前缀，并与长名称共享同一个值。
+//     PrintDefaults 和 PrintGrouped 不单独列出别名，而是在长名称的用法信息后注明。例如：
+//     Timeout time.Duration `flag:"request-timeout" alias:"old-timeout,timeout"`
+//   - 支持通过 `hidden:"true"` 标签或 "flag" 标签的 "hidden" 选项隐藏调试用的标志。隐藏的标志仍可正常设置，
+//     但不出现在 PrintDefaults 和 PrintGrouped 的输出中，其短选项和别名同样被隐藏。
+//     嵌套结构体上的 "hidden" 标签会隐藏其所有字段。例如：
+//     DebugAddr string `flag:"debug-addr" hidden:"true"`
+//   - 支持通过 "deprecated" 标签弃用标志。已弃用的标志仍可正常使用，但在命令行中第一次设置时
+//     会向 FlagSet 的输出打印一行警告，应用默认值时不会。用法信息中会注明 "DEPRECATED:" 及弃用说明。例如：
+//     OldTimeout int `flag:"old-timeout" deprecated:"use -request-timeout instead"`
+//   - 支持通过 `flag:"..."` 标签将一个 []string 字段标记为接收位置参数，解析后使用 BindArgs 填充。例如：
+//     Files []string `flag:"..."`
+//   - 支持通过 "group" 标签为用法信息分组，嵌套结构体上的 "group" 标签由其字段继承。
+//     PrintByGroup 按分组打印标志；使用 WithGroupedUsage 选项可以将其设置为 FlagSet 的用法函数。例如：
+//     Host string `flag:"host" group:"Database"`
+//   - 支持通过 "placeholder" 标签指定用法信息中的参数名称，代替 flag 包根据类型或用法信息中的反引号
+//     推断的名称（例如 "value"）。这只影响 PrintDefaults、PrintGrouped 和 PrintByGroup 的输出。例如：
+//     Listen string `flag:"listen" placeholder:"HOST:PORT" usage:"address to listen on"`
+//   - 支持通过 "example" 标签在用法信息的末尾附加示例值，例如 "allowed network (e.g. 10.0.0.0/8)"；
+//     没有 "usage" 标签时，用法信息仅为 "e.g. 10.0.0.0/8"。示例值同样包含在 Schema 的输出中。例如：
+//     Network string `flag:"network" usage:"allowed network" example:"10.0.0.0/8"`
+//   - 支持通过 "renamedFrom" 标签在重命名标志后保留旧名称。旧名称与别名一样加上所在结构体的前缀并共享同一个字段，
+//     但在命令行中第一次使用时会打印一行提示新名称的警告；在同一命令行中同时使用新旧名称会被报告为错误。
+//     PrintDefaults 和 PrintGrouped 不单独列出旧名称，而是在新名称的用法信息后注明。例如：
+//     DatabaseURL string `flag:"database-url" renamedFrom:"db-dsn"`
+//   - 支持以 "/" 开头的 "flag" 标签指定绝对名称，使深层嵌套的字段不加上所在结构体的前缀，
+//     只加上传递给 LoadTo 的前缀。字段在 PrintGrouped 中仍属于其所在结构体的分组。例如：
+//     Endpoint string `flag:"/otlp-endpoint"`
+//   - 支持通过 `count:"true"` 标签将整数字段声明为计数标志，每次在命令行中出现时加一，
+//     例如 "-v -v -v" 将字段设为 3；也可以使用 "-v=5" 直接设置计数。"-v=true" 与 "-v" 相同，"-v=false" 将计数清零。
+//     "default" 标签设置初始计数。例如：
+//     Verbosity int `flag:"v" count:"true"`
+//   - 支持通过 "env" 标签指定提供默认值的环境变量，在解析之前调用 ApplyEnv 读取。命令行中显式设置的标志优先，
+//     其次是环境变量，最后是 "default" 标签。用法信息中会注明环境变量的名称。例如：
+//     Port int `flag:"port" env:"APP_PORT" default:"8080"`
+//   - 支持通过 "derived" 标签注册派生标志，其参数先经过 RegisterTransform 注册的转换函数再设置字段，
+//     用于兼容需要换算的旧标志。派生标志与别名一样加上前缀，并在长名称的用法信息后注明。例如：
+//     Port int `flag:"port" derived:"port-plus-one=plus-one"`
+//   - 支持通过 `sensitive:"true"` 标签将密码等字段标记为敏感字段，其值在 Dump、Schema 和用法信息中
+//     显示为 "<redacted>"，字段实际的值不受影响。嵌套结构体上的 "sensitive" 标签作用于其所有字段。例如：
+//     Password string `flag:"password" sensitive:"true"`
+//   - 支持通过 "aliases" 标签为参数指定同义词，在设置字段之前替换为规范值，因此字段中保存的和默认值中显示的
+//     总是规范值。同义词同样作用于环境变量、配置文件和 Apply 中的值，可以与 RegisterFactory 注册的名称一起使用。
+//     注意 "aliases" 标签作用于参数，而 "alias" 标签作用于标志名称。例如 "-level warning" 将字段设为 "warn"：
+//     Level string `flag:"level" aliases:"warning=warn,err=error"`
+//   - 支持通过 `expand:"env"` 标签展开字符串字段的参数中的 $NAME 和 ${NAME}，"$$" 表示字面的 "$"。
+//     展开同样作用于环境变量、配置文件和 Apply 中的值，但不作用于字段加载时的值；"default" 标签总是按照其自身的规则展开。
+//     该标签默认关闭，因此不带该标签的字段中的 "$" 总是保持原样。例如 "-data-dir '$HOME/data'"：
+//     DataDir string `flag:"data-dir" expand:"env"`
+//   - 同时带有 `flag:"-"` 和 "env" 标签的字段不生成标志，也不出现在用法信息中，但在加载时读取环境变量
+//     （包括 WithDotEnv 指定的文件）并直接设置字段，适用于不希望出现在命令行历史中的配置。例如：
+//     Token string `flag:"-" env:"SECRET_TOKEN"`
+//   - 支持通过 "required-unless" 标签声明条件必需的标志：除非设置了标签中列出的任一标志，否则该标志必须被设置。
+//     与 required 选项相同，在解析后使用 CheckRequired 检查。例如 -config 在设置了 -stdin 时可以省略：
+//     Config string `flag:"config" required-unless:"stdin"`
+func LoadTo(fs *flag.FlagSet, prefix string, v interface{}, opts ...Option) {
+	if err := newLoader("LoadTo", fs, opts).loadAll(prefix, v); err != nil {
+		panic(err)
+	}
 	flag.Parse()
 }
 
-func load(fs *flag.FlagSet, prefix string, val reflect.Value) {
+// TryLoadTo 与 LoadTo 相同，但在发生错误时返回 error 而不是引发 panic。
+//
+// TryLoadTo 会在注册任何标志之前检查所有标志名称，因此返回错误时 fs 不会被修改。
+//
+// TryLoadTo 不会在遇到第一个问题时停止，而是报告所有字段中无效的默认值、标签、
+// 短选项和重复的标志名称。存在多个问题时，返回的错误由 errors.Join 合并，
+// 可以使用 errors.Is、errors.As 或 Unwrap() []error 检查其中的每一个错误。
+// 每个与字段有关的错误都包含该字段完整的 Go 字段路径，例如 "Config.Server.TLS.Port"，
+// 以便在标志名称有歧义的深层嵌套结构体中定位字段。
+func TryLoadTo(fs *flag.FlagSet, prefix string, v interface{}, opts ...Option) error {
+	return newLoader("TryLoadTo", fs, opts).loadAll(prefix, v)
+}
+
+// LoadResult 与 TryLoadTo 相同，但还会返回所有没有生成标志的字段及其原因，
+// 以便调用方确认没有重要的字段被静默忽略。
+func LoadResult(fs *flag.FlagSet, prefix string, v interface{}, opts ...Option) ([]Skipped, error) {
+	l := newLoader("LoadResult", fs, opts)
+	if err := l.loadAll(prefix, v); err != nil {
+		return nil, err
+	}
+	return l.skipped, nil
+}
+
+// LoadAllTo 依次将 vs 中的每个结构体加载到同一个 FlagSet 上，所有标志都使用相同的前缀。
+//
+// 重复标志名称的检查覆盖所有结构体，因此两个结构体生成同名标志时会报告错误。
+// vs 中的每个元素都必须是指向结构体的指针，其余规则与 LoadTo 相同。
+// 与 LoadTo 不同，LoadAllTo 不会调用 flag.Parse。
+func LoadAllTo(fs *flag.FlagSet, prefix string, vs ...interface{}) {
+	if err := newLoader("LoadAllTo", fs, nil).loadAll(prefix, vs...); err != nil {
+		panic(err)
+	}
+}
+
+// TryLoadAllTo 与 LoadAllTo 相同，但在发生错误时返回 error 而不是引发 panic。
+func TryLoadAllTo(fs *flag.FlagSet, prefix string, vs ...interface{}) error {
+	return newLoader("TryLoadAllTo", fs, nil).loadAll(prefix, vs...)
+}
+
+// NewFlagSet 创建一个名为 name、错误处理方式为 handling 的 FlagSet，将 v 加载到其上，
+// 并将其用法函数设置为使用 PrintGrouped 按嵌套结构体分组打印 v 的标志。
+// 之后直接调用 fs.Parse(os.Args[1:]) 即可。
+//
+// 加载失败时返回错误而不是引发 panic。prefix 和 opts 的含义与 LoadTo 相同。
+func NewFlagSet(name string, handling flag.ErrorHandling, prefix string, v interface{}, opts ...Option) (*flag.FlagSet, error) {
+	fs := flag.NewFlagSet(name, handling)
+	if err := newLoader("NewFlagSet", fs, opts).loadAll(prefix, v); err != nil {
+		return nil, err
+	}
+	fs.Usage = func() {
+		if fs.Name() == "" {
+			fmt.Fprintf(fs.Output(), "Usage:\n")
+		} else {
+			fmt.Fprintf(fs.Output(), "Usage of %s:\n", fs.Name())
+		}
+		PrintGrouped(fs, v, fs.Output())
+	}
+	return fs, nil
+}
+
+// newLoader 创建一个加载器，fn 为调用方的函数名称，用于错误信息。
+func newLoader(fn string, fs *flag.FlagSet, opts []Option) *loader {
+	return &loader{
+		fn:       fn,
+		fs:       fs,
+		opts:     newOptions(opts),
+		owners:   make(map[string]*field),
+		visiting: make(map[reflect.Type]bool),
+		presets:  make(map[*field]Source),
+	}
+}
+
+// loadAll 收集 vs 中所有结构体的标志，检查冲突后再统一注册。
+func (l *loader) loadAll(prefix string, vs ...interface{}) error {
+	if err := l.collect(prefix, vs...); err != nil {
+		return err
+	}
+	l.dropLoaded()
+	l.check()
+	l.applyDefaulters()
+	l.applyConfigFile()
+	l.setDefaults()
+	l.applyEnvOnly()
+	if err := l.err(); err != nil {
+		return err
+	}
+	l.register()
+	l.registerConfigFlag()
+	l.expandUsages()
+	l.record()
+	if l.opts.usage || l.opts.groupedUsage {
+		installUsage(l.fs, l.opts.groupedUsage)
+	}
+	return nil
+}
+
+// collect 收集 vs 中所有结构体将要生成的标志，但不修改 fs 或结构体。
+func (l *loader) collect(prefix string, vs ...interface{}) error {
+	for _, pattern := range l.opts.exclude {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("structflag: invalid exclude pattern %q: %v", pattern, err)
+		}
+	}
+	for _, pattern := range l.opts.include {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("structflag: invalid include pattern %q: %v", pattern, err)
+		}
+	}
+	for _, v := range vs {
+		val, err := l.structValue(v)
+		if err != nil {
+			return err
+		}
+		l.root = v
+		l.prefix = prefix
+		l.index = nil
+		l.loadStruct(prefix, typeName(val.Type()), val)
+	}
+	return nil
+}
+
+// setDefaults 为使用自定义 flag.Value 的字段应用默认值，以便在注册之前报告无效的默认值。
+//
+// 对于数值类型和 time.Duration 类型的字段，默认值按照字段类型解析（包括负数，例如 "-5" 和 "-30m"），
+// 无法解析或超出范围的默认值会被报告为错误，而不是静默地变为零值。
+func (l *loader) setDefaults() {
+	for _, f := range l.fields {
+		// 已由 WithFile 指定的配置文件设置的字段保留其值，不应用 "default" 标签。
+		if s, ok := l.presets[f]; ok {
+			f.hasDef = false
+			f.source = s
+		}
+		if f.hasDef {
+			f.source = Source{Kind: SourceDefault}
+		}
+		if f.value == nil && f.hasDef {
+			switch f.ptr.(type) {
+			case *bool, *int, *int64, *uint, *uint64, *float64, *time.Duration:
+				v, err := parseDefault(reflect.TypeOf(f.ptr).Elem(), f.def)
+				if err != nil {
+					l.fail(fmt.Errorf("structflag: invalid default %q for field %s: %v", f.def, f.path, err))
+					continue
+				}
+				f.defValue = v
+			}
+			continue
+		}
+		if f.value == nil || !f.hasDef {
+			continue
+		}
+		set := f.value.Set
+		if ds, ok := f.value.(defaultSetter); ok {
+			set = ds.SetDefault
+		}
+		if err := set(f.def); err != nil {
+			l.fail(fmt.Errorf("structflag: invalid default %q for field %s: %v", f.def, f.path, err))
+		}
+	}
+}
+
+// structValue 检查 v 是否为指向结构体的非 nil 指针，并返回其指向的结构体。
+func (l *loader) structValue(v interface{}) (reflect.Value, error) {
+	if v == nil {
+		return reflect.Value{}, fmt.Errorf("structflag: %s requires a non-nil pointer to a struct, got nil", l.fn)
+	}
+	rv := reflect.ValueOf(v)
+	switch {
+	case rv.Kind() != reflect.Ptr:
+		return reflect.Value{}, fmt.Errorf("structflag: %s requires a non-nil pointer to a struct, got %s", l.fn, rv.Type())
+	case rv.IsNil():
+		return reflect.Value{}, fmt.Errorf("structflag: %s requires a non-nil pointer to a struct, got nil %s", l.fn, rv.Type())
+	case rv.Elem().Kind() != reflect.Struct:
+		return reflect.Value{}, fmt.Errorf("structflag: %s requires a non-nil pointer to a struct, got %s", l.fn, rv.Type())
+	}
+	return rv.Elem(), nil
+}
+
+// typeName 返回用作字段路径起点的类型名称。匿名类型没有名称，此时使用其完整描述。
+func typeName(t reflect.Type) string {
+	if t.Name() != "" {
+		return t.Name()
+	}
+	return t.String()
+}
+
+// field 描述一个由结构体字段生成的标志。
+type field struct {
+	root        interface{} // 传递给 LoadTo 的结构体指针
+	addr        uintptr     // 字段的地址，用于识别重复加载的同一字段
+	path        string      // Go 字段路径，例如 "Config.Bar.Baz"
+	index       []int       // 字段在顶层结构体中的索引序列，见 reflect.Value.FieldByIndex
+	typ         reflect.Type
+	name        string
+	short       string
+	usage       string
+	def         string
+	hasDef      bool          // 是否设置了 default 标签；未设置时使用字段的当前值作为默认值
+	defValue    reflect.Value // 解析后的默认值，仅用于布尔、数值类型和 time.Duration 类型的字段
+	ptr         interface{}
+	value       flag.Value          // 非 nil 时使用 fs.Var 注册，此时忽略 ptr
+	mutex       string              // 互斥组名称，来自 "mutex" 标签
+	section     string              // 所属嵌套结构体的分组标题，顶层字段为空
+	group       string              // 用法信息中的分组，来自 "group" 标签
+	hideDefault bool                // 打印用法信息时不显示默认值
+	mask        string              // 打印用法信息时代替默认值显示的占位符
+	placeholder string              // 打印用法信息时代替参数名称显示的占位符，来自 "placeholder" 标签
+	foldCase    bool                // 长名称已转换为小写，NormalizeArgs 会将命令行中的名称转换为小写
+	required    bool                // 必须在命令行中设置，来自 "flag" 标签的 "required" 选项
+	unless      []string            // 除非设置了其中任一标志，否则必须在命令行中设置，来自 "required-unless" 标签，已加上所在结构体的前缀
+	aliases     []string            // 额外的长名称，来自 "alias" 标签，已加上所在结构体的前缀
+	derived     []derivedFlag       // 派生标志，来自 "derived" 标签
+	synonyms    map[string]string   // 参数的同义词 -> 规范值，来自 "aliases" 标签
+	expandEnv   bool                // 展开参数中的环境变量，来自 `expand:"env"` 标签
+	sensitive   bool                // 值在输出中被隐去，来自 "sensitive" 标签
+	renamedFrom string              // 重命名前的旧名称，来自 "renamedFrom" 标签，已加上所在结构体的前缀
+	hidden      bool                // 不出现在用法信息中
+	deprecated  string              // 弃用说明，来自 "deprecated" 标签
+	example     string              // 示例值，来自 "example" 标签
+	translate   func(string) string // 翻译用法信息中生成的文本，来自 WithUsageTranslator
+	env         string              // 提供默认值的环境变量，来自 "env" 标签
+	dotEnv      string              // 查找环境变量的 .env 文件，来自 WithDotEnv
+	source      Source              // 值的来源，不包括命令行中的标志，见 ValueSources
+}
+
+// usageText 返回注册标志时使用的用法信息。"example" 标签中的示例和已弃用字段的弃用说明
+// 依次附加在用法信息之后。
+func (f *field) usageText() string {
+	usage := annotate(f.usage, label(f, "e.g.")+" ", f.example)
+	return annotate(usage, label(f, "DEPRECATED:")+" ", f.deprecated)
+}
+
+// names 返回字段在 FlagSet 上注册的所有名称：长名称、短选项、旧名称、别名和派生标志。
+func (f *field) names() []string {
+	names := []string{f.name}
+	if f.short != "" {
+		names = append(names, f.short)
+	}
+	if f.renamedFrom != "" {
+		names = append(names, f.renamedFrom)
+	}
+	names = append(names, f.aliases...)
+	for _, d := range f.derived {
+		names = append(names, d.name)
+	}
+	return names
+}
+
+// displayDefault 返回输出中显示的标志 fl 的默认值：带有 "mask" 标签时为其占位符，
+// 敏感字段的非零默认值为 redacted，否则为 fl.DefValue。
+func (f *field) displayDefault(fl *flag.Flag) string {
+	switch {
+	case f.mask != "":
+		return f.mask
+	case f.sensitive && !isZeroValue(fl):
+		return redacted
+	}
+	return fl.DefValue
+}
+
+// choices 返回字段可以接受的值，例如通过 RegisterFactory 注册的名称。没有限制时返回 nil。
+func (f *field) choices() []string {
+	if fv, ok := f.value.(*factoryValue); ok {
+		return fv.names()
+	}
+	return nil
+}
+
+// label 返回用法信息中由 structflag 生成的文本 key（例如 "default"），
+// 如果字段 f 设置了 WithUsageTranslator，则返回其翻译。
+func label(f *field, key string) string {
+	if f == nil || f.translate == nil {
+		return key
+	}
+	return translate(f.translate, key)
+}
+
+// translate 使用 fn 翻译 s。fn 返回空字符串时使用原文。
+func translate(fn func(string) string, s string) string {
+	if fn == nil || s == "" {
+		return s
+	}
+	if t := fn(s); t != "" {
+		return t
+	}
+	return s
+}
+
+// annotate 将带有标签 label 的说明 note 附加在用法信息 usage 之后。
+// 如果 usage 为空，则用法信息仅为该说明。
+func annotate(usage, label, note string) string {
+	switch {
+	case note == "":
+		return usage
+	case usage == "":
+		return label + note
+	}
+	return usage + " (" + label + note + ")"
+}
+
+// loader 保存一次加载过程中收集到的标志。
+type loader struct {
+	fn         string
+	root       interface{} // 当前正在收集的结构体指针
+	prefix     string      // 传递给 LoadTo 的前缀
+	chain      []string    // 从顶层结构体到当前嵌套结构体的名称链
+	index      []int       // 当前嵌套结构体在顶层结构体中的索引序列，见 reflect.Value.FieldByIndex
+	section    string      // 当前嵌套结构体的分组标题
+	hidden     bool        // 当前嵌套结构体是否被隐藏
+	group      string      // 当前嵌套结构体的 "group" 标签
+	included   bool        // 当前嵌套结构体的前缀是否与 Include 的模式匹配
+	sensitive  bool        // 当前嵌套结构体是否为敏感字段
+	fs         *flag.FlagSet
+	opts       *options
+	fields     []*field
+	skipped    []Skipped
+	owners     map[string]*field     // 标志名称 -> 注册该名称的字段
+	visiting   map[reflect.Type]bool // 当前递归路径上的结构体类型
+	depth      int                   // 当前嵌套结构体的深度
+	errs       []error               // 收集过程中发现的所有错误
+	args       reflect.Value         // 接收位置参数的字段
+	argsPath   string                // 接收位置参数的字段的路径
+	defaulters []Defaulter           // 按调用顺序排列的 Defaulter
+	envOnly    []envOnlyField        // 只从环境变量读取的字段
+	presets    map[*field]Source     // 已由配置文件设置、不再应用 "default" 标签的字段
+}
+
+// fail 记录一个错误。加载器在发现错误后继续检查其余字段，以便一次报告所有问题。
+func (l *loader) fail(err error) {
+	l.errs = append(l.errs, err)
+}
+
+// err 返回由 joinErrors 合并的所有记录的错误。
+func (l *loader) err() error {
+	return joinErrors(l.errs)
+}
+
+// joinErrors 合并 errs：没有错误时返回 nil，只有一个错误时原样返回，否则使用 errors.Join 合并，
+// 每个错误仍可通过 errors.Is 和 errors.As 单独检查。
+func joinErrors(errs []error) error {
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	}
+	return errors.Join(errs...)
+}
+
+// loadStruct 在递归进入结构体 val 前检查循环引用和最大深度。
+func (l *loader) loadStruct(prefix, path string, val reflect.Value) {
+	t := val.Type()
+	if l.visiting[t] {
+		l.fail(fmt.Errorf("structflag: cyclic struct reference at %s", path))
+		return
+	}
+	if l.opts.maxDepth > 0 && l.depth >= l.opts.maxDepth {
+		l.fail(fmt.Errorf("structflag: maximum nesting depth %d exceeded at %s", l.opts.maxDepth, path))
+		return
+	}
+	l.visiting[t] = true
+	l.depth++
+	l.load(prefix, path, val)
+	l.depth--
+	delete(l.visiting, t)
+}
+
+func (l *loader) load(prefix, path string, val reflect.Value) {
+	var provider UsageProvider
+	if val.CanAddr() && val.Addr().CanInterface() {
+		provider, _ = val.Addr().Interface().(UsageProvider)
+	}
 	for i := 0; i < val.NumField(); i++ {
-		field := val.Type().Field(i)
-		usage := field.Tag.Get("usage")
-		flagValue := field.Tag.Get("flag")
-		defaultValue := field.Tag.Get("default")
-		short := field.Tag.Get("short")
+		sf := val.Type().Field(i)
+		usage := sf.Tag.Get("usage")
+		flagValue := sf.Tag.Get("flag")
+		defaultValue, hasDefault := sf.Tag.Lookup("default")
+		short, hasShort := sf.Tag.Lookup("short")
+
+		if err := l.checkTags(path+"."+sf.Name, sf); err != nil {
+			l.fail(err)
+		}
+
+		// 跳过标记为 `flag-"` 的结构体字段，以及被 WithFieldFilter 排除的字段。
+		// 同时带有 "short" 标签的 `flag:"-"` 字段只注册短选项，不注册长名称。
+		// 这里比较的是完整的标签，因此 `flag:"-,"` 不会被跳过，其名称为 "-"。
+		shortOnly := flagValue == "-" && hasShort
+		if (flagValue == "-" && !shortOnly) || (l.opts.filter != nil && !l.opts.filter(sf)) {
+			// 同时带有 "env" 标签的 `flag:"-"` 字段不生成标志，但在加载时从环境变量读取。
+			if env := sf.Tag.Get("env"); flagValue == "-" && env != "" && env != "-" {
+				l.envOnly = append(l.envOnly, envOnlyField{path: path + "." + sf.Name, env: env, value: val.Field(i)})
+			}
+			l.skip(path+"."+sf.Name, SkipIgnored)
+			continue
+		}
+		if shortOnly {
+			flagValue = ""
+		}
+
+		// "flag" 标签的名称之后可以带有逗号分隔的选项，例如 `flag:"verbose,short=v,required"`。
+		// 选项优先于单独的 "short"、"usage" 和 "default" 标签。
+		flagValue, tagOpts, err := parseFlagTag(flagValue)
+		if err != nil {
+			l.fail(fmt.Errorf("structflag: invalid flag tag on field %s.%s: %v", path, sf.Name, err))
+			continue
+		}
+		// 如果设置了 WithJSONNameFallback，没有 "flag" 标签名称的字段使用 "json" 标签中的名称。
+		if flagValue == "" && l.opts.jsonNameFallback {
+			if jsonValue := sf.Tag.Get("json"); jsonValue == "-" {
+				l.skip(path+"."+sf.Name, SkipIgnored)
+				continue
+			} else if jsonName := strings.Split(jsonValue, ",")[0]; jsonName != "" {
+				flagValue = jsonName
+			}
+		}
+
+		inline, required, hidden := false, false, false
+		for _, opt := range tagOpts {
+			switch opt.key {
+			case "inline", "squash":
+				inline = true
+			case "required":
+				required = true
+			case "hidden":
+				hidden = true
+			case "short":
+				short, hasShort = opt.value, true
+			case "usage":
+				usage = opt.value
+			case "default":
+				defaultValue, hasDefault = opt.value, true
+			default:
+				if l.opts.strictTags {
+					l.fail(fmt.Errorf("structflag: unknown flag tag option %q on field %s.%s", opt.key, path, sf.Name))
+				}
+			}
+		}
+
+		// "default" 标签中的 $NAME、${NAME} 和 ${NAME:-fallback} 在加载时使用环境变量展开。
+		if hasDefault {
+			if defaultValue, err = expandDefault(defaultValue); err != nil {
+				l.fail(fmt.Errorf("structflag: invalid default for field %s.%s: %v", path, sf.Name, err))
+				continue
+			}
+		}
+
+		// 如果设置了 WithSkipEmbeddedNonStruct，则跳过非结构体类型的嵌入字段。
+		if sf.Anonymous && l.opts.skipEmbeddedNonStruct && sf.Type.Kind() != reflect.Struct {
+			l.skip(path+"."+sf.Name, SkipEmbedded)
+			continue
+		}
+
+		// 使用 WithSetters 时，带有 setter 方法的未导出字段通过该方法设置。
+		var setter flag.Value
+		if sf.PkgPath != "" && l.opts.setters && !sf.Anonymous {
+			setter = setterFor(val, sf)
+		}
+
+		// 跳过未导出的字段。未导出的嵌入结构体仍会递归加载，因为其导出字段可以被访问。
+		// 然而，未导出字段上的 structflag 标签总是一个错误，因为该字段永远不会生成标志。
+		if sf.PkgPath != "" && setter == nil && !(sf.Anonymous && sf.Type.Kind() == reflect.Struct) {
+			if key, ok := hasTag(sf.Tag); ok {
+				l.fail(fmt.Errorf("structflag: %q tag on unexported field %s.%s has no effect", key, path, sf.Name))
+				continue
+			}
+			l.skip(path+"."+sf.Name, SkipUnexported)
+			continue
+		}
+
+		fieldPath := path + "." + sf.Name
+
+		// `flag:"..."` 标记的字段不生成标志，而是由 BindArgs 接收解析后剩余的位置参数。
+		if flagValue == "..." {
+			switch {
+			case sf.Type.Kind() != reflect.Slice || sf.Type.Elem().Kind() != reflect.String:
+				l.fail(fmt.Errorf("structflag: positional arguments field %s must be a []string, got %s", fieldPath, sf.Type))
+			case l.args.IsValid():
+				l.fail(fmt.Errorf("structflag: multiple positional arguments fields %s and %s", l.argsPath, fieldPath))
+			default:
+				l.args, l.argsPath = val.Field(i), fieldPath
+			}
+			continue
+		}
+
+		value := newValue(val.Field(i))
+		if setter != nil {
+			value = setter
+		}
+
+		// 带有 `count:"true"` 标签的整数字段每次设置时加一，例如 "-v -v -v"。
+		if v, ok := sf.Tag.Lookup("count"); ok {
+			count, err := strconv.ParseBool(v)
+			switch {
+			case err != nil:
+				l.fail(fmt.Errorf("structflag: invalid count tag %q on field %s", v, fieldPath))
+			case !count:
+			case setter != nil:
+				l.fail(fmt.Errorf("structflag: count tag on field %s with a setter method has no effect", fieldPath))
+			case !isInteger(sf.Type):
+				l.fail(fmt.Errorf("structflag: count tag on field %s requires an integer type, got %s", fieldPath, sf.Type))
+			default:
+				value = &countValue{field: val.Field(i)}
+			}
+		}
+
+		// time.Time 字段的命令行参数按照 "layout" 标签解析，默认为 time.RFC3339。
+		if layout, ok := sf.Tag.Lookup("layout"); ok {
+			if tv, isTime := value.(*timeValue); isTime {
+				tv.layout = layout
+			} else if setter != nil {
+				l.fail(fmt.Errorf("structflag: layout tag on field %s with a setter method has no effect", fieldPath))
+			} else {
+				l.fail(fmt.Errorf("structflag: layout tag on field %s requires a time.Time type, got %s", fieldPath, sf.Type))
+			}
+		}
+
+		// 嵌套结构体的字段名称用作其前缀，此时去掉 WithTrimStructSuffix 指定的后缀。
+		fieldName := sf.Name
+		nested := value == nil && val.Field(i).Kind() == reflect.Struct
+		if nested {
+			fieldName = l.trimStructSuffix(fieldName)
+		}
+		name := l.canonical(l.flagName(prefix, fieldName, flagValue, nested))
+
+		// 如果设置了 WithFlattenEmbedded，没有 "flag" 标签名称的嵌入结构体如同带有 "inline" 选项。
+		if l.opts.flattenEmbedded && sf.Anonymous && flagValue == "" && value == nil && val.Field(i).Kind() == reflect.Struct {
+			inline = true
+		}
+		// `noprefix:"true"` 的嵌套结构体无论 "flag" 标签如何都不增加前缀，与 "inline" 选项相同。
+		if v, ok := sf.Tag.Lookup("noprefix"); ok {
+			noprefix, err := strconv.ParseBool(v)
+			switch {
+			case err != nil:
+				l.fail(fmt.Errorf("structflag: invalid noprefix tag %q on field %s", v, fieldPath))
+			case noprefix && (value != nil || val.Field(i).Kind() != reflect.Struct):
+				l.fail(fmt.Errorf("structflag: noprefix tag on non-struct field %s has no effect", fieldPath))
+				continue
+			case noprefix:
+				inline = true
+			}
+		}
+		if inline && (value != nil || val.Field(i).Kind() != reflect.Struct) {
+			l.fail(fmt.Errorf("structflag: inline option on non-struct field %s has no effect", fieldPath))
+			continue
+		}
+
+		// 嵌套结构体本身不会生成标志，因此其上的 "default" 和 "short" 标签没有意义。
+		if value == nil && val.Field(i).Kind() == reflect.Struct {
+			if hasDefault {
+				l.fail(fmt.Errorf("structflag: %q tag on struct field %s has no effect", "default", fieldPath))
+			}
+			if hasShort {
+				l.fail(fmt.Errorf("structflag: %q tag on struct field %s has no effect", "short", fieldPath))
+			}
+			for _, key := range []string{"alias", "deprecated", "renamedFrom", "env", "derived", "required-unless"} {
+				if _, ok := sf.Tag.Lookup(key); ok {
+					l.fail(fmt.Errorf("structflag: %q tag on struct field %s has no effect", key, fieldPath))
+				}
+			}
+		}
+
+		if hasShort {
+			var err error
+			if short, err = normalizeShort(short, name, fieldPath); err != nil {
+				l.fail(err)
+				short = ""
+			}
+		}
+
+		// 只有短选项的字段以短选项作为其名称。与其他短选项一样，它不加上任何前缀，也不受 WithCaseInsensitive 影响。
+		if shortOnly {
+			if short == "" {
+				continue
+			}
+			name, short = short, ""
+		}
+
+		// 被 Exclude 排除的字段与 `flag:"-"` 一样被跳过。嵌套结构体被排除时跳过其中的所有字段。
+		if !inline && matchAny(l.opts.exclude, name) {
+			l.skip(fieldPath, SkipIgnored)
+			continue
+		}
 
-		// 跳过标记为 `flag-"` 的结构体字段
-		if flagValue == "-" {
+		// 设置了 Include 时，只有名称匹配的字段或所在嵌套结构体的前缀匹配的字段生成标志。
+		// 嵌套结构体总是被递归加载，以便包含其中匹配的字段。
+		included := len(l.opts.include) == 0 || l.included || (!inline && matchAny(l.opts.include, name))
+		if !included && (value != nil || val.Field(i).Kind() != reflect.Struct) {
+			l.skip(fieldPath, SkipIgnored)
 			continue
 		}
 
-		// 标志名称按照 `flag:"xxx"` 标签的值命名。如果未提供，则默认使用字段名称。
-		//
-		// 这类似于 encoding/json 包的默认行为。
-		name := field.Name
-		if flagValue != "" {
-			name = flagValue
+		hideDefault := false
+		if v, ok := sf.Tag.Lookup("show-default"); ok {
+			show, err := strconv.ParseBool(v)
+			if err != nil {
+				l.fail(fmt.Errorf("structflag: invalid show-default tag %q on field %s", v, fieldPath))
+			}
+			hideDefault = !show
+		}
+
+		// 隐藏的标志仍可正常设置，只是不出现在用法信息中。嵌套结构体上的 "hidden" 标签会隐藏其所有字段。
+		if v, ok := sf.Tag.Lookup("hidden"); ok && !hidden {
+			if hidden, err = strconv.ParseBool(v); err != nil {
+				l.fail(fmt.Errorf("structflag: invalid hidden tag %q on field %s", v, fieldPath))
+			}
+		}
+		hidden = hidden || l.hidden
+
+		// 敏感字段的值不会出现在 Dump、Schema 和用法信息中。嵌套结构体上的 "sensitive" 标签作用于其所有字段。
+		sensitive := l.sensitive
+		if v, ok := sf.Tag.Lookup("sensitive"); ok && !sensitive {
+			if sensitive, err = strconv.ParseBool(v); err != nil {
+				l.fail(fmt.Errorf("structflag: invalid sensitive tag %q on field %s", v, fieldPath))
+			}
 		}
 
-		// 假设前缀为 "prefix-"，则标志名称为 "prefix-name"。
-		//
-		// 然而，如果前缀为空，则标志名称仅为 "name"，没有额外的破折号。
-		if prefix != "" {
-			name = prefix + "-" + name
+		// 没有 "group" 标签的字段继承所在嵌套结构体的分组。
+		group := sf.Tag.Get("group")
+		if group == "" {
+			group = l.group
+		}
+
+		// "usage" 标签的值和弃用说明作为消息键交给 WithUsageTranslator 翻译。
+		// UsageProvider 提供的用法信息优先于 "usage" 标签，WithUsageMap 中的用法信息优先于两者。
+		usage = translate(l.opts.translateUsage, usage)
+		if provider != nil {
+			if u, ok := provider.FlagUsage(sf.Name); ok {
+				usage = u
+			}
+		}
+		if u, ok := l.opts.usages[name]; ok {
+			usage = u
+		}
+		if l.opts.strictTags {
+			if _, unknown := expandPlaceholders(usage, func(key string) (string, bool) { return "", usagePlaceholders[key] }); len(unknown) > 0 {
+				l.fail(fmt.Errorf("structflag: unknown usage placeholder {%s} on field %s", unknown[0], fieldPath))
+			}
+		}
+
+		var renamedFrom string
+		if v, ok := sf.Tag.Lookup("renamedFrom"); ok {
+			renamedFrom = l.canonical(l.joinName(prefix, v))
+		}
+		derived, err := l.derived(prefix, sf.Tag.Get("derived"), fieldPath)
+		if err != nil {
+			l.fail(err)
+		}
+		synonyms, err := parseSynonyms(sf.Tag.Get("aliases"), fieldPath)
+		if err != nil {
+			l.fail(err)
+		}
+		if to, ok := synonyms[defaultValue]; ok && hasDefault {
+			defaultValue = to
+		}
+		expandEnv, err := parseExpand(sf, fieldPath)
+		if err != nil {
+			l.fail(err)
+		}
+
+		index := append(append([]int(nil), l.index...), i)
+		f := &field{
+			root:        l.root,
+			index:       index,
+			addr:        val.Field(i).UnsafeAddr(),
+			path:        fieldPath,
+			typ:         sf.Type,
+			name:        name,
+			short:       short,
+			usage:       usage,
+			def:         defaultValue,
+			hasDef:      hasDefault,
+			required:    required,
+			hidden:      hidden,
+			deprecated:  translate(l.opts.translateUsage, sf.Tag.Get("deprecated")),
+			translate:   l.opts.translateUsage,
+			mutex:       sf.Tag.Get("mutex"),
+			section:     l.section,
+			group:       group,
+			hideDefault: hideDefault,
+			mask:        sf.Tag.Get("mask"),
+			placeholder: sf.Tag.Get("placeholder"),
+			foldCase:    l.opts.caseInsensitive && !shortOnly,
+			example:     sf.Tag.Get("example"),
+			env:         l.env(sf, name),
+			dotEnv:      l.opts.dotEnv,
+			aliases:     l.aliases(prefix, sf.Tag.Get("alias")),
+			unless:      l.aliases(prefix, sf.Tag.Get("required-unless")),
+			renamedFrom: renamedFrom,
+			derived:     derived,
+			synonyms:    synonyms,
+			expandEnv:   expandEnv,
+			sensitive:   sensitive,
+		}
+
+		// 对于 flag 包不原生支持的类型，使用自定义的 flag.Value。
+		if f.value = value; f.value != nil {
+			l.fields = append(l.fields, f)
+			l.addDefaulter(val.Field(i))
+			continue
 		}
 
 		switch val.Field(i).Kind() {
 		case reflect.Struct:
-			load(fs, name, val.Field(i))
-		case reflect.Bool, reflect.Int64, reflect.Float64, reflect.Int, reflect.Uint, reflect.Uint64, reflect.String:
-			switch f := val.Field(i).Addr().Interface().(type) {
-			case *bool:
-				defaultBool := defaultValue == "true"
-				fs.BoolVar(f, name, defaultBool, usage)
-				if short != "" {
-					fs.BoolVar(f, short, defaultBool, usage)
-				}
-			case *time.Duration:
-				defaultDuration, _ := time.ParseDuration(defaultValue)
-				fs.DurationVar(f, name, defaultDuration, usage)
-				if short != "" {
-					fs.DurationVar(f, short, defaultDuration, usage)
-				}
-			case *float64:
-				defaultFloat64, _ := strconv.ParseFloat(defaultValue, 64)
-				fs.Float64Var(f, name, defaultFloat64, usage)
-				if short != "" {
-					fs.Float64Var(f, short, defaultFloat64, usage)
-				}
-			case *int:
-				defaultInt, _ := strconv.Atoi(defaultValue)
-				fs.IntVar(f, name, defaultInt, usage)
-				if short != "" {
-					fs.IntVar(f, short, defaultInt, usage)
-				}
-			case *int64:
-				defaultInt64, _ := strconv.ParseInt(defaultValue, 10, 64)
-				fs.Int64Var(f, name, defaultInt64, usage)
-				if short != "" {
-					fs.Int64Var(f, short, defaultInt64, usage)
-				}
-			case *string:
-				fs.StringVar(f, name, defaultValue, usage)
-				if short != "" {
-					fs.StringVar(f, short, defaultValue, usage)
-				}
-			case *uint:
-				defaultUint, _ := strconv.ParseUint(defaultValue, 10, 32)
-				fs.UintVar(f, name, uint(defaultUint), usage)
-				if short != "" {
-					fs.UintVar(f, short, uint(defaultUint), usage)
-				}
-			case *uint64:
-				defaultUint64, _ := strconv.ParseUint(defaultValue, 10, 64)
-				fs.Uint64Var(f, name, defaultUint64, usage)
-				if short != "" {
-					fs.Uint64Var(f, short, defaultUint64, usage)
-				}
+			parentHidden, parentGroup, parentIncluded, parentSensitive, parentIndex := l.hidden, l.group, l.included, l.sensitive, l.index
+			l.hidden, l.group, l.included, l.sensitive, l.index = hidden, group, included, sensitive, index
+
+			// 带有 "inline" 或 "squash" 选项的嵌套结构体不增加前缀，其字段如同直接定义在外层结构体中。
+			if inline {
+				l.loadStruct(prefix, fieldPath, val.Field(i))
+				l.hidden, l.group, l.included, l.sensitive, l.index = parentHidden, parentGroup, parentIncluded, parentSensitive, parentIndex
+				continue
+			}
+
+			// 嵌套结构体的 "usage" 标签用作其标志分组的标题。
+			section := l.section
+			l.section = usage
+			if l.section == "" {
+				l.section = name
 			}
+			segment := fieldName
+			if flagValue != "" {
+				segment = strings.TrimPrefix(flagValue, "/")
+			}
+			l.chain = append(l.chain, segment)
+			l.loadStruct(name, fieldPath, val.Field(i))
+			l.chain = l.chain[:len(l.chain)-1]
+			l.section = section
+			l.hidden, l.group, l.included, l.sensitive, l.index = parentHidden, parentGroup, parentIncluded, parentSensitive, parentIndex
+		case reflect.Bool, reflect.Int64, reflect.Float64, reflect.Int, reflect.Uint, reflect.Uint64, reflect.String:
+			f.ptr = val.Field(i).Addr().Interface()
+			l.fields = append(l.fields, f)
+			l.addDefaulter(val.Field(i))
 		default:
-			return
+			// 指向当前递归路径上的结构体的指针是循环引用，而不是普通的不支持的类型。
+			if t := sf.Type; t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Struct && l.visiting[t.Elem()] {
+				l.fail(fmt.Errorf("structflag: cyclic struct reference at %s", fieldPath))
+				continue
+			}
+			l.skip(fieldPath, SkipUnsupportedKind)
+		}
+	}
+	l.addDefaulter(val)
+}
+
+// flagName 计算字段对应的标志名称，prefix 为其所在结构体的前缀，fieldName 为字段名称，
+// flagValue 为其 "flag" 标签，nested 表示该字段为嵌套结构体，其名称用作前缀。
+func (l *loader) flagName(prefix, fieldName, flagValue string, nested bool) string {
+	// 以 "/" 开头的 "flag" 标签表示绝对名称，不加上所在结构体的前缀，只加上传递给 LoadTo 的前缀。
+	if strings.HasPrefix(flagValue, "/") {
+		return l.joinName(l.prefix, flagValue[1:])
+	}
+
+	// 如果设置了 WithNameMapper，则没有 "flag" 标签的字段的完整名称（不含传递给 LoadTo 的前缀）
+	// 由映射函数根据字段名称链决定。
+	if flagValue == "" && l.opts.mapName != nil {
+		chain := make([]string, len(l.chain), len(l.chain)+1)
+		copy(chain, l.chain)
+		return l.joinName(l.prefix, l.opts.mapName(append(chain, fieldName)))
+	}
+
+	// 标志名称按照 `flag:"xxx"` 标签的值命名。如果未提供，则默认使用字段名称。
+	//
+	// 这类似于 encoding/json 包的默认行为。
+	//
+	// 如果设置了命名选项（例如 WithLowercase），则只转换由字段名称得到的名称。
+	// 嵌套结构体的前缀只有在设置了 WithConvertPrefixes 时才会被转换。
+	name := fieldName
+	if flagValue != "" {
+		name = flagValue
+	} else if l.opts.convertName != nil && (!nested || l.opts.convertPrefixes) {
+		name = l.opts.convertName(name)
+	}
+	return l.joinName(prefix, name)
+}
+
+// aliases 将 "alias" 标签中逗号分隔的名称加上所在结构体的前缀 prefix。
+// 与短选项不同，别名是完整的长名称，因此与字段自身的名称一样加上前缀。
+func (l *loader) aliases(prefix, tag string) []string {
+	if tag == "" {
+		return nil
+	}
+	var aliases []string
+	for _, alias := range strings.Split(tag, ",") {
+		aliases = append(aliases, l.canonical(l.joinName(prefix, strings.TrimSpace(alias))))
+	}
+	return aliases
+}
+
+// canonical 返回长名称 name 注册时使用的形式。如果设置了 WithCaseInsensitive，则转换为小写。
+func (l *loader) canonical(name string) string {
+	if l.opts.caseInsensitive {
+		return strings.ToLower(name)
+	}
+	return name
+}
+
+// trimStructSuffix 去掉嵌套结构体字段名称 name 的 WithTrimStructSuffix 后缀。
+// 如果去掉后缀后名称为空，则返回原始名称。
+func (l *loader) trimStructSuffix(name string) string {
+	if trimmed := strings.TrimSuffix(name, l.opts.trimStructSuffix); trimmed != "" {
+		return trimmed
+	}
+	return name
+}
+
+// joinName 将前缀与名称连接起来。
+//
+// 假设前缀为 "prefix-"，则标志名称为 "prefix-name"。
+//
+// 然而，如果前缀为空，则标志名称仅为 "name"，没有额外的破折号。
+//
+// 如果设置了 WithSeparator，则使用指定的分隔符代替破折号。
+func (l *loader) joinName(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	sep := l.opts.separator
+	if sep == "" {
+		sep = "-"
+	}
+	return prefix + sep + name
+}
+
+// tagKeys 是 structflag 识别的所有结构体标签。
+var tagKeys = []string{"flag", "usage", "default", "short", "mutex", "show-default", "mask", "alias", "hidden", "deprecated", "group", "placeholder", "example", "renamedFrom", "count", "env", "derived", "layout", "sensitive", "noprefix", "aliases", "expand", "required-unless"}
+
+// hasTag 报告 tag 中是否包含任何 structflag 标签，并返回找到的第一个标签。
+func hasTag(tag reflect.StructTag) (string, bool) {
+	for _, key := range tagKeys {
+		if _, ok := tag.Lookup(key); ok {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+// normalizeShort 去掉短选项前导的破折号，并检查其是否恰好为一个字符且与长名称不同。
+func normalizeShort(short, name, path string) (string, error) {
+	s := strings.TrimLeft(short, "-")
+	switch {
+	case s == "":
+		return "", fmt.Errorf("structflag: empty short flag %q for field %s", short, path)
+	case utf8.RuneCountInString(s) != 1:
+		return "", fmt.Errorf("structflag: short flag %q of field %s must be a single character", short, path)
+	case s == name:
+		return "", fmt.Errorf("structflag: short flag %q of field %s is the same as its long name", short, path)
+	}
+	return s, nil
+}
+
+// check 检查收集到的标志名称之间以及与 fs 中已有标志之间是否存在冲突。
+//
+// 长名称先于短选项登记，因此短选项与长名称之间的冲突总是归咎于短选项。
+// 如果设置了 WithDropConflictingShorts，冲突的短选项将被丢弃而不是报告错误。
+// 与 FlagSet 中已有标志的冲突按照 OnConflict 设置的策略处理。
+func (l *loader) check() {
+	fields := l.fields[:0]
+	for _, f := range l.fields {
+		if l.opts.onConflict == ConflictSkip && l.fs.Lookup(f.name) != nil {
+			l.skip(f.path, SkipConflict)
+			continue
+		}
+		fields = append(fields, f)
+		if err := validateName(f.name); err != nil {
+			l.fail(fmt.Errorf("structflag: invalid flag name %q for field %s: %v", f.name, f.path, err))
+			continue
+		}
+		if f.short != "" {
+			if err := validateName(f.short); err != nil {
+				l.fail(fmt.Errorf("structflag: invalid short flag %q for field %s: %v", f.short, f.path, err))
+				f.short = ""
+			}
+		}
+		if err := l.claim(f, f.name, "flag"); err != nil {
+			l.fail(err)
+		}
+	}
+	l.fields = fields
+	for _, f := range l.fields {
+		aliases := f.aliases[:0]
+		for _, alias := range f.aliases {
+			if err := validateName(alias); err != nil {
+				l.fail(fmt.Errorf("structflag: invalid alias %q for field %s: %v", alias, f.path, err))
+				continue
+			}
+			if l.opts.onConflict == ConflictSkip && l.fs.Lookup(alias) != nil {
+				continue
+			}
+			if err := l.claim(f, alias, "alias"); err != nil {
+				l.fail(err)
+			}
+			aliases = append(aliases, alias)
+		}
+		f.aliases = aliases
+		derived := f.derived[:0]
+		for _, d := range f.derived {
+			if err := validateName(d.name); err != nil {
+				l.fail(fmt.Errorf("structflag: invalid derived flag %q for field %s: %v", d.name, f.path, err))
+				continue
+			}
+			if l.opts.onConflict == ConflictSkip && l.fs.Lookup(d.name) != nil {
+				continue
+			}
+			if err := l.claim(f, d.name, "derived flag"); err != nil {
+				l.fail(err)
+			}
+			derived = append(derived, d)
+		}
+		f.derived = derived
+		if f.renamedFrom == "" {
+			continue
+		}
+		if err := validateName(f.renamedFrom); err != nil {
+			l.fail(fmt.Errorf("structflag: invalid renamedFrom %q for field %s: %v", f.renamedFrom, f.path, err))
+		} else if err := l.claim(f, f.renamedFrom, "old name"); err != nil {
+			l.fail(err)
+		}
+	}
+	for _, f := range l.fields {
+		if f.short == "" {
+			continue
+		}
+		if l.opts.onConflict == ConflictSkip && l.fs.Lookup(f.short) != nil {
+			f.short = ""
+			continue
+		}
+		if err := l.claim(f, f.short, "short flag"); err != nil {
+			if !l.opts.dropConflictingShorts {
+				l.fail(err)
+			}
+			f.short = ""
+		}
+	}
+	if l.opts.autoShort {
+		l.assignShorts()
+	}
+	if name := l.opts.configFlag; name != "" {
+		if owner, ok := l.owners[name]; ok {
+			l.fail(fmt.Errorf("structflag: config flag -%s conflicts with field %s", name, owner.path))
+		}
+	}
+	envs := make(map[string]*field)
+	for _, f := range l.fields {
+		if f.env == "" {
+			continue
+		}
+		if owner, ok := envs[f.env]; ok {
+			l.fail(fmt.Errorf("structflag: environment variable %q of field %s conflicts with field %s", f.env, f.path, owner.path))
+			continue
+		}
+		envs[f.env] = f
+	}
+}
+
+// assignShorts 为没有短选项的字段分配其名称中第一个未被占用的字母作为短选项，用于 AutoShort。
+func (l *loader) assignShorts() {
+	for _, f := range l.fields {
+		if f.short != "" || f.hidden || f.deprecated != "" || utf8.RuneCountInString(f.name) <= 1 {
+			continue
+		}
+		for _, r := range f.name {
+			short := string(r)
+			if !unicode.IsLetter(r) || l.owners[short] != nil || l.fs.Lookup(short) != nil {
+				continue
+			}
+			l.owners[short] = f
+			f.short = short
+			break
+		}
+	}
+}
+
+// validateName 检查标志名称是否可以在命令行中使用。Unicode 字母是允许的，
+// 但名称不能为空，不能包含空白字符或 "="，也不能以 "-" 开头。
+func validateName(name string) error {
+	switch {
+	case name == "":
+		return errors.New("empty name")
+	case strings.HasPrefix(name, "-"):
+		return errors.New("name starts with '-'")
+	case strings.Contains(name, "="):
+		return errors.New("name contains '='")
+	case strings.IndexFunc(name, unicode.IsSpace) >= 0:
+		return errors.New("name contains whitespace")
+	}
+	return nil
+}
+
+// claim 将标志名称 name 登记为属于字段 f，如果该名称已被占用则返回错误。
+func (l *loader) claim(f *field, name, kind string) error {
+	if owner, ok := l.owners[name]; ok {
+		return fmt.Errorf("structflag: %s %q of field %s conflicts with field %s", kind, name, f.path, owner.path)
+	}
+	if l.fs.Lookup(name) != nil {
+		err := fmt.Errorf("structflag: %s %q of field %s is already defined in the FlagSet", kind, name, f.path)
+		if prev := lookup(l.fs, name); prev != nil {
+			err = fmt.Errorf("structflag: %s %q of field %s is already defined in the FlagSet by field %s", kind, name, f.path, prev.path)
+		}
+		if l.opts.onConflict == ConflictPanic {
+			panic(err)
+		}
+		return err
+	}
+	l.owners[name] = f
+	return nil
+}
+
+// register 将收集到的标志注册到 fs 上。
+func (l *loader) register() {
+	fs := l.fs
+	for _, fl := range l.fields {
+		name, short, usage, defaultValue := fl.name, fl.short, fl.usageText(), fl.def
+		if fl.value != nil {
+			fs.Var(fl.value, name, usage)
+			if short != "" {
+				fs.Var(fl.value, short, usage)
+			}
+			l.registerExtra(fl)
+			continue
+		}
+		switch f := fl.ptr.(type) {
+		case *bool:
+			defaultBool := *f
+			if fl.hasDef {
+				defaultBool = fl.defValue.Bool()
+			}
+			fs.BoolVar(f, name, defaultBool, usage)
+			if short != "" {
+				fs.BoolVar(f, short, defaultBool, usage)
+			}
+		case *time.Duration:
+			defaultDuration := *f
+			if fl.hasDef {
+				defaultDuration = time.Duration(fl.defValue.Int())
+			}
+			fs.DurationVar(f, name, defaultDuration, usage)
+			if short != "" {
+				fs.DurationVar(f, short, defaultDuration, usage)
+			}
+		case *float64:
+			defaultFloat64 := *f
+			if fl.hasDef {
+				defaultFloat64 = fl.defValue.Float()
+			}
+			fs.Float64Var(f, name, defaultFloat64, usage)
+			if short != "" {
+				fs.Float64Var(f, short, defaultFloat64, usage)
+			}
+		case *int:
+			defaultInt := *f
+			if fl.hasDef {
+				defaultInt = int(fl.defValue.Int())
+			}
+			fs.IntVar(f, name, defaultInt, usage)
+			if short != "" {
+				fs.IntVar(f, short, defaultInt, usage)
+			}
+		case *int64:
+			defaultInt64 := *f
+			if fl.hasDef {
+				defaultInt64 = fl.defValue.Int()
+			}
+			fs.Int64Var(f, name, defaultInt64, usage)
+			if short != "" {
+				fs.Int64Var(f, short, defaultInt64, usage)
+			}
+		case *string:
+			defaultString := *f
+			if fl.hasDef {
+				defaultString = defaultValue
+			}
+			fs.StringVar(f, name, defaultString, usage)
+			if short != "" {
+				fs.StringVar(f, short, defaultString, usage)
+			}
+		case *uint:
+			defaultUint := *f
+			if fl.hasDef {
+				defaultUint = uint(fl.defValue.Uint())
+			}
+			fs.UintVar(f, name, defaultUint, usage)
+			if short != "" {
+				fs.UintVar(f, short, defaultUint, usage)
+			}
+		case *uint64:
+			defaultUint64 := *f
+			if fl.hasDef {
+				defaultUint64 = fl.defValue.Uint()
+			}
+			fs.Uint64Var(f, name, defaultUint64, usage)
+			if short != "" {
+				fs.Uint64Var(f, short, defaultUint64, usage)
+			}
+		}
+		l.registerExtra(fl)
+	}
+}
+
+// registerExtra 在字段 f 的长名称和短选项注册之后，为带有 "expand" 或 "aliases" 标签、已弃用或重命名的字段包装其 flag.Value，
+// 并注册其旧名称、别名和派生标志。它们与长名称共享同一个字段。
+func (l *loader) registerExtra(f *field) {
+	if len(f.synonyms) > 0 || f.expandEnv {
+		fl := l.fs.Lookup(f.name)
+		fl.Value = &argumentValue{Value: fl.Value, f: f}
+		if f.short != "" {
+			l.fs.Lookup(f.short).Value = fl.Value
+		}
+	}
+	if f.deprecated != "" {
+		fl := l.fs.Lookup(f.name)
+		fl.Value = &deprecatedValue{Value: fl.Value, fs: l.fs, name: f.name, msg: f.deprecated}
+		if f.short != "" {
+			l.fs.Lookup(f.short).Value = fl.Value
+		}
+	}
+	if f.renamedFrom != "" {
+		fl := l.fs.Lookup(f.name)
+		r := &rename{fs: l.fs, from: f.renamedFrom, to: f.name}
+		fl.Value = &renamedValue{Value: fl.Value, rename: r}
+		if f.short != "" {
+			l.fs.Lookup(f.short).Value = fl.Value
+		}
+		l.fs.Var(&renamedValue{Value: fl.Value.(*renamedValue).Value, rename: r, old: true}, f.renamedFrom, f.usageText())
+	}
+	for _, alias := range f.aliases {
+		l.fs.Var(l.fs.Lookup(f.name).Value, alias, f.usageText())
+	}
+	for _, d := range f.derived {
+		l.fs.Var(&derivedValue{Value: l.fs.Lookup(f.name).Value, transform: d.transform}, d.name, f.usageText())
+	}
+}
+
+// expandUsages 在注册之后展开用法信息中的占位符，此时可以得到标志实际的默认值。
+// 标志的所有名称（短选项、别名和旧名称）共享展开后的用法信息。
+func (l *loader) expandUsages() {
+	for _, f := range l.fields {
+		if !strings.Contains(f.usage, "{") {
+			continue
+		}
+		fl := l.fs.Lookup(f.name)
+		old := f.usageText()
+		f.usage, _ = expandPlaceholders(f.usage, func(key string) (string, bool) {
+			switch key {
+			case "default":
+				return f.displayDefault(fl), true
+			case "choices":
+				return strings.Join(f.choices(), ", "), true
+			case "env":
+				return f.env, true
+			}
+			return "", false
+		})
+		usage := f.usageText()
+		for _, name := range f.names() {
+			if fl := l.fs.Lookup(name); fl != nil && fl.Usage == old {
+				fl.Usage = usage
+			}
+		}
+	}
+}
+
+// env 返回字段 sf 对应的环境变量：优先使用 "env" 标签，`env:"-"` 表示没有对应的环境变量；
+// 没有 "env" 标签时，如果设置了 WithEnvPrefix，则根据标志名称 name 得到。
+func (l *loader) env(sf reflect.StructField, name string) string {
+	if env, ok := sf.Tag.Lookup("env"); ok {
+		if env == "-" {
+			return ""
+		}
+		return env
+	}
+	if !l.opts.autoEnv {
+		return ""
+	}
+	env := strings.Map(func(r rune) rune {
+		if r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToUpper(r)
+		}
+		return '_'
+	}, name)
+	if l.opts.envPrefix != "" {
+		env = l.opts.envPrefix + "_" + env
+	}
+	return env
+}
+
+// matchAny 判断 name 是否与 patterns 中任一 glob 模式匹配。
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
 		}
 	}
+	return false
 }
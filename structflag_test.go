@@ -0,0 +1,309 @@
+package structflag
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestInvalidTarget(t *testing.T) {
+	type config struct {
+		Name string
+	}
+	var m map[string]string
+	tests := []struct {
+		v    interface{}
+		want string
+	}{
+		{nil, "structflag: TryLoadTo requires a non-nil pointer to a struct, got nil"},
+		{(*config)(nil), "structflag: TryLoadTo requires a non-nil pointer to a struct, got nil *structflag.config"},
+		{config{}, "structflag: TryLoadTo requires a non-nil pointer to a struct, got structflag.config"},
+		{&m, "structflag: TryLoadTo requires a non-nil pointer to a struct, got *map[string]string"},
+	}
+	for _, tt := range tests {
+		err := TryLoadTo(flag.NewFlagSet("test", flag.ContinueOnError), "", tt.v)
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("TryLoadTo(%#v) = %v, want %q", tt.v, err, tt.want)
+		}
+	}
+
+	defer func() {
+		want := "structflag: LoadTo requires a non-nil pointer to a struct, got structflag.config"
+		if r := recover(); fmt.Sprint(r) != want {
+			t.Errorf("LoadTo panicked with %v, want %q", r, want)
+		}
+	}()
+	LoadTo(flag.NewFlagSet("test", flag.ContinueOnError), "", config{})
+}
+
+func TestRepeatedLoad(t *testing.T) {
+	defer func(fs *flag.FlagSet, args []string) {
+		flag.CommandLine, os.Args = fs, args
+	}(flag.CommandLine, os.Args)
+	flag.CommandLine = flag.NewFlagSet("test", flag.ContinueOnError)
+	os.Args = []string{"test", "-name", "x"}
+
+	type config struct {
+		Name string `flag:"name"`
+	}
+	var cfg config
+	Load(&cfg)
+	Load(&cfg)
+	if cfg.Name != "x" {
+		t.Errorf("Name = %q, want %q", cfg.Name, "x")
+	}
+	if err := TryLoadTo(flag.CommandLine, "", &cfg); err != nil {
+		t.Errorf("TryLoadTo on the same struct: %v", err)
+	}
+
+	type other struct {
+		Name string `flag:"name"`
+	}
+	want := `structflag: flag "name" of field other.Name is already defined in the FlagSet by field config.Name`
+	if err := TryLoadTo(flag.CommandLine, "", &other{}); err == nil || err.Error() != want {
+		t.Errorf("TryLoadTo on another struct = %v, want %q", err, want)
+	}
+
+	flag.CommandLine.String("plain", "", "")
+	var plain struct {
+		Plain string `flag:"plain"`
+	}
+	if err := TryLoadTo(flag.CommandLine, "", &plain); err == nil || !strings.Contains(err.Error(), "is already defined in the FlagSet") {
+		t.Errorf("TryLoadTo over a flag not registered by structflag = %v", err)
+	}
+}
+
+func TestWithSeparator(t *testing.T) {
+	type pool struct {
+		Max int `flag:"max"`
+	}
+	type db struct {
+		Host string `flag:"host"`
+		Pool pool   `flag:"pool"`
+	}
+	type config struct {
+		DB db `flag:"db"`
+	}
+	for _, sep := range []string{".", "_"} {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		if err := TryLoadTo(fs, "app", &config{}, WithSeparator(sep)); err != nil {
+			t.Fatalf("TryLoadTo(%q): %v", sep, err)
+		}
+		for _, name := range []string{"app" + sep + "db" + sep + "host", "app" + sep + "db" + sep + "pool" + sep + "max"} {
+			if fs.Lookup(name) == nil {
+				t.Errorf("WithSeparator(%q): flag %s is not registered", sep, name)
+			}
+		}
+	}
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	if err := TryLoadTo(fs, "", &config{}); err != nil {
+		t.Fatalf("TryLoadTo: %v", err)
+	}
+	if fs.Lookup("db-pool-max") == nil {
+		t.Error("flag db-pool-max is not registered with the default separator")
+	}
+}
+
+type pathConfig struct {
+	Server struct {
+		TLS struct {
+			Port    int    `flag:"port" default:"https"`
+			Cert    string `flag:"cert" short:"cert"`
+			Timeout string `flag:"timeout,bogus"`
+		} `flag:"tls"`
+	} `flag:"server"`
+}
+
+func TestErrorFieldPath(t *testing.T) {
+	err := TryLoadTo(flag.NewFlagSet("test", flag.ContinueOnError), "", &pathConfig{}, WithStrictTags())
+	if err == nil {
+		t.Fatal("TryLoadTo did not report an error")
+	}
+	for _, path := range []string{"pathConfig.Server.TLS.Port", "pathConfig.Server.TLS.Cert", "pathConfig.Server.TLS.Timeout"} {
+		if !strings.Contains(err.Error(), path) {
+			t.Errorf("error does not mention %s:\n%v", path, err)
+		}
+	}
+}
+
+type cyclicConfig struct {
+	Name string
+	Self *cyclicConfig
+}
+
+type cyclicOuter struct {
+	Inner cyclicInner
+}
+
+type cyclicInner struct {
+	Outer *cyclicOuter
+}
+
+type repeatedConfig struct {
+	A repeatedAddr `flag:"a"`
+	B repeatedAddr `flag:"b"`
+}
+
+type repeatedAddr struct {
+	Host string `flag:"host"`
+}
+
+func TestCyclicStructReference(t *testing.T) {
+	tests := []struct {
+		v    interface{}
+		want string
+	}{
+		{&cyclicConfig{}, "structflag: cyclic struct reference at cyclicConfig.Self"},
+		{&cyclicOuter{}, "structflag: cyclic struct reference at cyclicOuter.Inner.Outer"},
+	}
+	for _, tt := range tests {
+		err := TryLoadTo(flag.NewFlagSet("test", flag.ContinueOnError), "", tt.v)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("TryLoadTo(%T) = %v, want %q", tt.v, err, tt.want)
+		}
+	}
+
+	// 同一类型出现在兄弟字段中不是循环引用。
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	if err := TryLoadTo(fs, "", &repeatedConfig{}); err != nil {
+		t.Fatalf("TryLoadTo: %v", err)
+	}
+	for _, name := range []string{"a-host", "b-host"} {
+		if fs.Lookup(name) == nil {
+			t.Errorf("flag %s is not registered", name)
+		}
+	}
+}
+
+func TestNoPrefix(t *testing.T) {
+	type server struct {
+		Host string `flag:"host"`
+	}
+	type log struct {
+		Level string `flag:"level"`
+	}
+	type metrics struct {
+		Addr string `flag:"addr"`
+		Log  log    `flag:"log"`
+	}
+	type config struct {
+		Name    string  `flag:"name"`
+		Server  server  `flag:"server"`
+		Log     log     `flag:"logging" noprefix:"true"`
+		Metrics metrics `flag:",inline"`
+	}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	if err := TryLoadTo(fs, "", &config{}); err != nil {
+		t.Fatalf("TryLoadTo: %v", err)
+	}
+	for _, name := range []string{"name", "server-host", "level", "addr", "log-level"} {
+		if fs.Lookup(name) == nil {
+			t.Errorf("flag %s is not registered", name)
+		}
+	}
+	if fs.Lookup("logging-level") != nil {
+		t.Error(`noprefix:"true" did not drop the prefix from the flag tag`)
+	}
+
+	fs = flag.NewFlagSet("test", flag.ContinueOnError)
+	if err := TryLoadTo(fs, "app", &config{}); err != nil {
+		t.Fatalf("TryLoadTo: %v", err)
+	}
+	for _, name := range []string{"app-name", "app-server-host", "app-level", "app-addr", "app-log-level"} {
+		if fs.Lookup(name) == nil {
+			t.Errorf("flag %s is not registered with prefix app", name)
+		}
+	}
+}
+
+func TestSharedStructFlagSets(t *testing.T) {
+	var cfg struct {
+		Verbose bool     `flag:"verbose"`
+		Tags    []string `flag:"tag"`
+		Count   int      `flag:"v" count:"true"`
+	}
+	serve := flag.NewFlagSet("serve", flag.ContinueOnError)
+	build := flag.NewFlagSet("build", flag.ContinueOnError)
+	for _, fs := range []*flag.FlagSet{serve, build} {
+		if err := TryLoadTo(fs, "", &cfg); err != nil {
+			t.Fatalf("TryLoadTo(%s): %v", fs.Name(), err)
+		}
+	}
+
+	if err := serve.Parse([]string{"-verbose", "-tag", "a", "-tag", "b", "-v"}); err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if !cfg.Verbose || !reflect.DeepEqual(cfg.Tags, []string{"a", "b"}) || cfg.Count != 1 {
+		t.Errorf("after serve: cfg = %+v", cfg)
+	}
+
+	// 切片在每个 FlagSet 上独立地累积，计数在当前值上继续累加。
+	if err := build.Parse([]string{"-tag", "c", "-v"}); err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if !cfg.Verbose || !reflect.DeepEqual(cfg.Tags, []string{"c"}) || cfg.Count != 2 {
+		t.Errorf("after build: cfg = %+v", cfg)
+	}
+}
+
+// Count 是用于测试非结构体类型嵌入字段的类型。
+type Count int
+
+func TestEmbeddedNonStruct(t *testing.T) {
+	type config struct {
+		Count
+		Name string `flag:"name"`
+	}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	var cfg config
+	if err := TryLoadTo(fs, "", &cfg); err != nil {
+		t.Fatalf("TryLoadTo: %v", err)
+	}
+	if err := fs.Parse([]string{"-Count", "3"}); err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if cfg.Count != 3 {
+		t.Errorf("Count = %d, want 3", cfg.Count)
+	}
+
+	fs = flag.NewFlagSet("test", flag.ContinueOnError)
+	if err := TryLoadTo(fs, "", &config{}, WithSkipEmbeddedNonStruct()); err != nil {
+		t.Fatalf("TryLoadTo: %v", err)
+	}
+	if fs.Lookup("Count") != nil {
+		t.Error("WithSkipEmbeddedNonStruct registered flag Count")
+	}
+	if fs.Lookup("name") == nil {
+		t.Error("flag name is not registered")
+	}
+
+	type renamed struct {
+		Count `flag:"n"`
+	}
+	fs = flag.NewFlagSet("test", flag.ContinueOnError)
+	if err := TryLoadTo(fs, "", &renamed{}); err != nil {
+		t.Fatalf("TryLoadTo: %v", err)
+	}
+	if fs.Lookup("n") == nil || fs.Lookup("Count") != nil {
+		t.Error(`embedded field tagged flag:"n" is not registered as n`)
+	}
+}
+
+func TestJoinErrors(t *testing.T) {
+	if err := joinErrors(nil); err != nil {
+		t.Errorf("joinErrors(nil) = %v, want nil", err)
+	}
+	a, b := fmt.Errorf("a"), fmt.Errorf("b")
+	if err := joinErrors([]error{a}); err != a {
+		t.Errorf("joinErrors with one error = %v, want the error itself", err)
+	}
+	err := joinErrors([]error{a, b})
+	if !errors.Is(err, a) || !errors.Is(err, b) || err.Error() != "a\nb" {
+		t.Errorf("joinErrors with two errors = %q", err)
+	}
+}
@@ -0,0 +1,65 @@
+package structflag
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// parseSynonyms 解析 "aliases" 标签，例如 "warning=warn,err=error"，返回同义词到规范值的映射。
+func parseSynonyms(tag, path string) (map[string]string, error) {
+	if tag == "" {
+		return nil, nil
+	}
+	synonyms := make(map[string]string)
+	for _, item := range strings.Split(tag, ",") {
+		from, to, ok := strings.Cut(strings.TrimSpace(item), "=")
+		if !ok || from == "" || to == "" {
+			return nil, fmt.Errorf("structflag: invalid aliases tag %q on field %s: want synonym=value", tag, path)
+		}
+		synonyms[from] = to
+	}
+	return synonyms, nil
+}
+
+// canonicalValue 返回参数 s 的规范值：s 为 "aliases" 标签中的同义词时返回其对应的值，否则原样返回 s。
+func (f *field) canonicalValue(s string) string {
+	if to, ok := f.synonyms[s]; ok {
+		return to
+	}
+	return s
+}
+
+// argumentValue 包装带有 "expand" 或 "aliases" 标签的字段的 flag.Value，在设置字段之前使用 argument 处理参数，
+// 因此字段中保存的以及 String 返回的总是展开后的规范值。
+type argumentValue struct {
+	flag.Value
+	f *field
+}
+
+func (v *argumentValue) Set(s string) error {
+	return v.Value.Set(v.f.argument(s))
+}
+
+func (v *argumentValue) SetDefault(s string) error {
+	if ds, ok := v.Value.(defaultSetter); ok {
+		return ds.SetDefault(v.f.argument(s))
+	}
+	return v.Value.Set(v.f.argument(s))
+}
+
+func (v *argumentValue) String() string {
+	if v == nil || v.Value == nil {
+		return ""
+	}
+	return v.Value.String()
+}
+
+func (v *argumentValue) IsBoolFlag() bool {
+	b, ok := v.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
+
+func (v *argumentValue) unwrap() flag.Value {
+	return v.Value
+}
@@ -0,0 +1,68 @@
+package structflag
+
+import (
+	"errors"
+	"strings"
+)
+
+// tagOption 是 "flag" 标签中名称之后的一个选项，例如 "required" 或 "short=v"。
+type tagOption struct {
+	key      string
+	value    string
+	hasValue bool
+}
+
+// parseFlagTag 将 "flag" 标签拆分为名称和逗号分隔的选项，
+// 例如 `flag:"verbose,short=v,required"` 的名称为 "verbose"，选项为 short=v 和 required。
+//
+// 选项的值中可以使用反斜杠转义逗号（例如 `usage=a\, b`），也可以用单引号括起来
+// （例如 `usage='a, b'`）；引号内的反斜杠同样可以转义单引号和反斜杠本身。
+// 空的选项被忽略，因此 `flag:"-,"` 和 `flag:"name,"` 中末尾的逗号不会产生选项。
+func parseFlagTag(tag string) (string, []tagOption, error) {
+	parts, err := splitTag(tag)
+	if err != nil {
+		return "", nil, err
+	}
+	opts := make([]tagOption, 0, len(parts)-1)
+	for _, part := range parts[1:] {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		key, value, hasValue := part, "", false
+		if i := strings.IndexByte(part, '='); i >= 0 {
+			key, value, hasValue = part[:i], part[i+1:], true
+		}
+		opts = append(opts, tagOption{key: strings.TrimSpace(key), value: value, hasValue: hasValue})
+	}
+	return parts[0], opts, nil
+}
+
+// splitTag 按照未转义、未被单引号括起来的逗号拆分 tag，并去掉转义字符和引号。
+func splitTag(tag string) ([]string, error) {
+	var (
+		parts  []string
+		b      strings.Builder
+		quoted bool
+	)
+	for i := 0; i < len(tag); i++ {
+		switch c := tag[i]; {
+		case c == '\\':
+			if i+1 == len(tag) {
+				return nil, errors.New("trailing backslash")
+			}
+			i++
+			b.WriteByte(tag[i])
+		case c == '\'':
+			quoted = !quoted
+		case c == ',' && !quoted:
+			parts = append(parts, b.String())
+			b.Reset()
+		default:
+			b.WriteByte(c)
+		}
+	}
+	if quoted {
+		return nil, errors.New("unterminated quote")
+	}
+	return append(parts, b.String()), nil
+}
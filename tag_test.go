@@ -0,0 +1,137 @@
+package structflag
+
+import (
+	"flag"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestParseFlagTag(t *testing.T) {
+	tests := []struct {
+		tag  string
+		name string
+		opts []tagOption
+	}{
+		{"verbose", "verbose", []tagOption{}},
+		{"verbose,short=v,required", "verbose", []tagOption{{key: "short", value: "v", hasValue: true}, {key: "required"}}},
+		{"-,", "-", []tagOption{}},
+		{"name,", "name", []tagOption{}},
+		{"name,,required, ", "name", []tagOption{{key: "required"}}},
+		{",short=v", "", []tagOption{{key: "short", value: "v", hasValue: true}}},
+		{`name,usage=a\, b`, "name", []tagOption{{key: "usage", value: "a, b", hasValue: true}}},
+		{`name,usage='a, b'`, "name", []tagOption{{key: "usage", value: "a, b", hasValue: true}}},
+	}
+	for _, tt := range tests {
+		name, opts, err := parseFlagTag(tt.tag)
+		if err != nil {
+			t.Errorf("parseFlagTag(%q): %v", tt.tag, err)
+			continue
+		}
+		if name != tt.name || !reflect.DeepEqual(opts, tt.opts) {
+			t.Errorf("parseFlagTag(%q) = %q, %+v, want %q, %+v", tt.tag, name, opts, tt.name, tt.opts)
+		}
+	}
+
+	for _, tag := range []string{`name\`, `name,usage='a`} {
+		if _, _, err := parseFlagTag(tag); err == nil {
+			t.Errorf("parseFlagTag(%q) did not report an error", tag)
+		}
+	}
+}
+
+func TestStrictTagsTrailingComma(t *testing.T) {
+	var cfg struct {
+		Dash string `flag:"-,"`
+		Name string `flag:"name,"`
+	}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	if err := TryLoadTo(fs, "p", &cfg, WithStrictTags()); err != nil {
+		t.Fatalf("TryLoadTo: %v", err)
+	}
+	for _, name := range []string{"p--", "p-name"} {
+		if fs.Lookup(name) == nil {
+			t.Errorf("flag %s is not registered", name)
+		}
+	}
+}
+
+func TestFlagTagOptions(t *testing.T) {
+	type config struct {
+		Verbose bool   `flag:"verbose,short=v,required,hidden"`
+		Name    string `flag:",required"`
+		Level   int    `flag:"level,short=l,usage='level, 0-9',default=3" short:"x" usage:"ignored" default:"1"`
+		Host    string `flag:"host" short:"H" usage:"the host" default:"localhost"`
+	}
+	var cfg config
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	var buf strings.Builder
+	fs.SetOutput(&buf)
+	if err := TryLoadTo(fs, "", &cfg, WithStrictTags(), WithUsage()); err != nil {
+		t.Fatalf("TryLoadTo: %v", err)
+	}
+	for _, name := range []string{"verbose", "v", "Name", "level", "l", "host", "H"} {
+		if fs.Lookup(name) == nil {
+			t.Errorf("flag %s is not registered", name)
+		}
+	}
+	if fs.Lookup("x") != nil {
+		t.Error("short tag is used although the flag tag sets short")
+	}
+	if cfg.Level != 3 || cfg.Host != "localhost" {
+		t.Errorf("cfg = %+v", cfg)
+	}
+	if got := fs.Lookup("level").Usage; got != "level, 0-9" {
+		t.Errorf("usage of level = %q", got)
+	}
+
+	fs.Usage()
+	if strings.Contains(buf.String(), "verbose") {
+		t.Errorf("hidden flag appears in usage:\n%s", buf.String())
+	}
+
+	if err := fs.Parse(nil); err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	err := CheckRequired(fs, &cfg)
+	if err == nil || !strings.Contains(err.Error(), "verbose") || !strings.Contains(err.Error(), "Name") {
+		t.Errorf("CheckRequired = %v, want errors for verbose and Name", err)
+	}
+
+	var unknown struct {
+		Name string `flag:"name,bogus"`
+	}
+	want := `structflag: unknown flag tag option "bogus" on field`
+	if err := TryLoadTo(flag.NewFlagSet("test", flag.ContinueOnError), "", &unknown, WithStrictTags()); err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("TryLoadTo with WithStrictTags = %v, want %q", err, want)
+	}
+	if err := TryLoadTo(flag.NewFlagSet("test", flag.ContinueOnError), "", &unknown); err != nil {
+		t.Errorf("TryLoadTo without WithStrictTags: %v", err)
+	}
+}
+
+func TestDerivedBoolFlag(t *testing.T) {
+	RegisterTransform("structflag-test-negate", func(s string) (string, error) {
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return "", err
+		}
+		return strconv.FormatBool(!b), nil
+	})
+	t.Cleanup(func() { RegisterTransform("structflag-test-negate", nil) })
+
+	cfg := struct {
+		Quiet bool `flag:"quiet" derived:"loud=structflag-test-negate"`
+	}{Quiet: true}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	if err := TryLoadTo(fs, "", &cfg); err != nil {
+		t.Fatalf("TryLoadTo: %v", err)
+	}
+	if err := fs.Parse([]string{"-loud"}); err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if cfg.Quiet {
+		t.Error("Quiet = true after -loud, want false")
+	}
+}
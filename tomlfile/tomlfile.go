@@ -0,0 +1,40 @@
+// Package tomlfile 从 TOML 配置文件中加载 structflag 使用的结构体。
+//
+// 与 yamlfile 包相同，TOML 支持放在这个单独的包中，只有导入该包的程序才会编译和链接 github.com/BurntSushi/toml。
+// 该包与 structflag 属于同一个模块，因此 github.com/BurntSushi/toml 仍出现在 structflag 模块的依赖中。
+package tomlfile
+
+import (
+	"github.com/BurntSushi/toml"
+	"github.com/MUMU-DADA/structflag"
+)
+
+func init() {
+	structflag.RegisterFileFormat(".toml", decodeMap)
+}
+
+// LoadFile 与 structflag.LoadFile 相同，但读取 TOML 文件：TOML 键与 structflag 为字段生成的
+// 标志名称匹配，表对应嵌套结构体，数组对应切片字段。
+//
+// TOML 的日期时间值直接设置 time.Time 字段；整数和浮点数是不同的类型，整数字段对应的值为浮点数时
+// 报告错误，而不是截断。类型不匹配的错误信息包含完整的键路径，例如 "db.max-conns"；
+// TOML 语法错误的错误信息包含行号。opts 应与加载 v 时使用的相同。
+// 在 structflag.LoadTo 之前调用 LoadFile 即可让文件中的值成为标志的默认值，并由命令行标志覆盖，
+// 但带有 "default" 标签的字段仍使用标签中的值；需要文件优先时，请在 LoadTo 中使用 structflag.WithFile：
+//
+//	if err := tomlfile.LoadFile("config.toml", &cfg); err != nil && !errors.Is(err, os.ErrNotExist) {
+//		log.Fatal(err)
+//	}
+//	structflag.Load(&cfg)
+func LoadFile(path string, v interface{}, opts ...structflag.Option) error {
+	return structflag.LoadFileWith(path, v, decodeMap, opts...)
+}
+
+// decodeMap 将 TOML 文档解码为 structflag.LoadFileWith 使用的映射。
+func decodeMap(data []byte) (map[string]interface{}, error) {
+	var doc map[string]interface{}
+	if _, err := toml.Decode(string(data), &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
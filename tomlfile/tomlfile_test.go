@@ -0,0 +1,76 @@
+package tomlfile
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type dbConfig struct {
+	MaxConns int           `flag:"max-conns"`
+	Timeout  time.Duration `flag:"timeout"`
+}
+
+type config struct {
+	Name    string    `flag:"name"`
+	Hosts   []string  `flag:"hosts"`
+	Started time.Time `flag:"started"`
+	DB      dbConfig  `flag:"db"`
+}
+
+func writeFile(t *testing.T, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadFile(t *testing.T) {
+	path := writeFile(t, `name = "api"
+hosts = ["a", "b"]
+started = 2024-05-01T12:30:00Z
+
+[db]
+max-conns = 10
+timeout = "250ms"
+`)
+	var cfg config
+	if err := LoadFile(path, &cfg); err != nil {
+		t.Fatalf("LoadFile: %v", err)
+	}
+	want := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	if cfg.Name != "api" || strings.Join(cfg.Hosts, ",") != "a,b" || !cfg.Started.Equal(want) ||
+		cfg.DB.MaxConns != 10 || cfg.DB.Timeout != 250*time.Millisecond {
+		t.Errorf("cfg = %+v", cfg)
+	}
+}
+
+func TestLoadFileErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []string
+	}{
+		{"key path", "[db]\nmax-conns = \"many\"\n", []string{"db.max-conns", "invalid int"}},
+		{"float for int", "[db]\nmax-conns = 1.5\n", []string{"db.max-conns", "float 1.5 for integer type int"}},
+		{"unknown keys", "nme = \"x\"\n[db]\nmax = 1\n", []string{"unknown keys", "db.max, nme"}},
+		{"syntax", "name = \"api\"\n\n[db]\nmax-conns = \n", []string{"line 4"}},
+	}
+	for _, tt := range tests {
+		var cfg config
+		err := LoadFile(writeFile(t, tt.data), &cfg)
+		if err == nil {
+			t.Errorf("%s: LoadFile succeeded", tt.name)
+			continue
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("%s: LoadFile = %v, want an error containing %q", tt.name, err, want)
+			}
+		}
+	}
+}
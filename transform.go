@@ -0,0 +1,99 @@
+package structflag
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// TransformFunc 在派生标志被设置时转换命令行参数，转换后的值再用于设置字段。
+type TransformFunc func(s string) (string, error)
+
+var transforms = struct {
+	sync.RWMutex
+	m map[string]TransformFunc
+}{m: make(map[string]TransformFunc)}
+
+// RegisterTransform 以名称 name 注册一个转换函数，供 "derived" 标签引用。
+//
+// "derived" 标签为字段注册额外的派生标志，格式为逗号分隔的 "标志名称=转换函数名称"。
+// 派生标志与别名一样加上所在结构体的前缀并共享同一个字段，但其参数先经过转换函数再设置字段。
+// 例如为兼容旧的命令行保留一个把端口加一的标志：
+//
+//	structflag.RegisterTransform("plus-one", func(s string) (string, error) {
+//		n, err := strconv.Atoi(s)
+//		if err != nil {
+//			return "", err
+//		}
+//		return strconv.Itoa(n + 1), nil
+//	})
+//
+//	Port int `flag:"port" short:"p" derived:"port-plus-one=plus-one"`
+//
+// 对同一名称重复注册将替换之前的转换函数；transform 为 nil 时取消注册。
+// 引用未注册的转换函数会在加载时报告错误。
+func RegisterTransform(name string, transform TransformFunc) {
+	transforms.Lock()
+	defer transforms.Unlock()
+	if transform == nil {
+		delete(transforms.m, name)
+		return
+	}
+	transforms.m[name] = transform
+}
+
+func lookupTransform(name string) TransformFunc {
+	transforms.RLock()
+	defer transforms.RUnlock()
+	return transforms.m[name]
+}
+
+// derivedFlag 是由 "derived" 标签声明的派生标志。
+type derivedFlag struct {
+	name      string // 已加上所在结构体的前缀
+	transform TransformFunc
+}
+
+// derived 解析字段 path 的 "derived" 标签，将其中的标志名称加上所在结构体的前缀 prefix。
+func (l *loader) derived(prefix, tag, path string) ([]derivedFlag, error) {
+	if tag == "" {
+		return nil, nil
+	}
+	var derived []derivedFlag
+	for _, item := range strings.Split(tag, ",") {
+		name, transform, ok := strings.Cut(strings.TrimSpace(item), "=")
+		if !ok || name == "" || transform == "" {
+			return nil, fmt.Errorf("structflag: invalid derived tag %q on field %s: want name=transform", tag, path)
+		}
+		fn := lookupTransform(transform)
+		if fn == nil {
+			return nil, fmt.Errorf("structflag: unknown transform %q in derived tag on field %s", transform, path)
+		}
+		derived = append(derived, derivedFlag{name: l.canonical(l.joinName(prefix, name)), transform: fn})
+	}
+	return derived, nil
+}
+
+// derivedValue 在设置字段之前使用转换函数转换参数。
+type derivedValue struct {
+	flag.Value
+	transform TransformFunc
+}
+
+func (v *derivedValue) Set(s string) error {
+	t, err := v.transform(s)
+	if err != nil {
+		return err
+	}
+	return v.Value.Set(t)
+}
+
+func (v *derivedValue) IsBoolFlag() bool {
+	b, ok := v.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
+
+func (v *derivedValue) unwrap() flag.Value {
+	return v.Value
+}
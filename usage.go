@@ -0,0 +1,348 @@
+package structflag
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// UsageProvider 由需要在运行时生成用法信息的结构体实现，例如列出当前编译进程序的存储后端。
+//
+// 加载结构体（包括嵌套结构体）时，对其每个字段调用 FlagUsage，fieldName 为 Go 字段名称。
+// 如果返回的 bool 为 true，则使用返回的字符串作为该字段的用法信息，否则使用 "usage" 标签。
+type UsageProvider interface {
+	FlagUsage(fieldName string) (string, bool)
+}
+
+// usagePlaceholders 是用法信息中可以使用的占位符。
+var usagePlaceholders = map[string]bool{
+	"default": true,
+	"choices": true,
+	"env":     true,
+}
+
+// expandPlaceholders 将 usage 中的 {key} 替换为 lookup 返回的值，"{{" 表示字面的 "{"。
+// lookup 不认识的占位符原样保留，并在 unknown 中返回其 key。
+func expandPlaceholders(usage string, lookup func(key string) (string, bool)) (expanded string, unknown []string) {
+	var b strings.Builder
+	for {
+		i := strings.IndexByte(usage, '{')
+		if i < 0 {
+			b.WriteString(usage)
+			return b.String(), unknown
+		}
+		b.WriteString(usage[:i])
+		usage = usage[i:]
+		if strings.HasPrefix(usage, "{{") {
+			b.WriteByte('{')
+			usage = usage[2:]
+			continue
+		}
+		j := strings.IndexByte(usage, '}')
+		if j < 0 {
+			b.WriteString(usage)
+			return b.String(), unknown
+		}
+		key := usage[1:j]
+		if v, ok := lookup(key); ok {
+			b.WriteString(v)
+		} else {
+			b.WriteString(usage[:j+1])
+			unknown = append(unknown, key)
+		}
+		usage = usage[j+1:]
+	}
+}
+
+// installUsage 将 fs 的用法函数替换为使用 PrintDefaults 的版本，如果 grouped 为 true 则使用 PrintByGroup。
+// 只在使用 WithUsage 或 WithGroupedUsage 选项时调用。
+func installUsage(fs *flag.FlagSet, grouped bool) {
+	usage := func() {
+		if fs.Name() == "" {
+			fmt.Fprintf(fs.Output(), "Usage:\n")
+		} else {
+			fmt.Fprintf(fs.Output(), "Usage of %s:\n", fs.Name())
+		}
+		if grouped {
+			PrintByGroup(fs, fs.Output())
+		} else {
+			PrintDefaults(fs)
+		}
+	}
+	if fs == flag.CommandLine {
+		// flag.CommandLine 的用法函数总是调用 flag.Usage。
+		flag.Usage = usage
+		return
+	}
+	fs.Usage = usage
+}
+
+// PrintDefaults 与 fs.PrintDefaults 类似，打印 fs 中所有标志的用法信息，
+// 但由 structflag 注册的短选项会与其长名称显示在同一行，例如：
+//
+//	-f, -foo int
+//	  	usage (default 42)
+//
+// 不是由 structflag 注册的标志按照 flag 包的格式原样打印。
+func PrintDefaults(fs *flag.FlagSet) {
+	fs.VisitAll(func(fl *flag.Flag) {
+		if f := lookup(fs, fl.Name); f != nil && (fl.Name != f.name || f.hidden) {
+			// 短选项和别名与其长名称一起打印，隐藏的标志不打印。
+			return
+		}
+		writeFlag(fs.Output(), fs, fl, "")
+	})
+}
+
+// PrintByGroup 将 fs 中的所有标志按照 "group" 标签分组打印到 w。
+//
+// 分组按照在结构体中首次出现的顺序排列，组内的标志按照字段顺序排列并缩进。
+// 嵌套结构体上的 "group" 标签由其中没有 "group" 标签的字段继承。
+// 没有分组的标志（包括不是由 structflag 注册的标志）按名称排序打印在最后的 "Options" 分组中。
+// 与 PrintDefaults 相同，短选项和别名与其长名称显示在同一行，隐藏的标志不会打印。
+func PrintByGroup(fs *flag.FlagSet, w io.Writer) {
+	var titles []string
+	groups := make(map[string][]*field)
+	for _, f := range fieldsOf(fs, nil) {
+		if f.group == "" || f.hidden || fs.Lookup(f.name) == nil {
+			continue
+		}
+		if _, ok := groups[f.group]; !ok {
+			titles = append(titles, f.group)
+		}
+		groups[f.group] = append(groups[f.group], f)
+	}
+	for i, title := range titles {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "%s:\n", title)
+		for _, f := range groups[title] {
+			writeFlag(w, fs, fs.Lookup(f.name), "  ")
+		}
+	}
+
+	var rest []*flag.Flag
+	fs.VisitAll(func(fl *flag.Flag) {
+		if f := lookup(fs, fl.Name); f != nil && (fl.Name != f.name || f.hidden || f.group != "") {
+			return
+		}
+		rest = append(rest, fl)
+	})
+	if len(rest) == 0 {
+		return
+	}
+	if len(titles) > 0 {
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintf(w, "Options:\n")
+	for _, fl := range rest {
+		writeFlag(w, fs, fl, "  ")
+	}
+}
+
+// PrintGrouped 将 v 生成的标志按照嵌套结构体分组打印到 w。
+//
+// 顶层字段的标志打印在 "Options" 标题下；每个嵌套结构体的标志打印在以该结构体字段的
+// "usage" 标签为标题的分组中，如果没有 "usage" 标签，则以其标志前缀为标题。
+// 分组按照在结构体中首次出现的顺序排列，组内的标志按照字段顺序排列并缩进。
+//
+// v 必须是此前通过 LoadTo 加载到 fs 上的结构体指针。
+func PrintGrouped(fs *flag.FlagSet, v interface{}, w io.Writer) {
+	var titles []string
+	groups := make(map[string][]*field)
+	for _, f := range fieldsOf(fs, v) {
+		if f.hidden {
+			continue
+		}
+		title := f.section
+		if title == "" {
+			title = "Options"
+		}
+		if _, ok := groups[title]; !ok {
+			titles = append(titles, title)
+		}
+		groups[title] = append(groups[title], f)
+	}
+	for i, title := range titles {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "%s:\n", title)
+		for _, f := range groups[title] {
+			if fl := fs.Lookup(f.name); fl != nil {
+				writeFlag(w, fs, fl, "  ")
+			}
+		}
+	}
+}
+
+// writeFlag 按照 flag 包的格式将标志 fl 的用法信息写入 w，每行前加上 indent。
+// 由 structflag 注册的短选项会与长名称显示在同一行。
+func writeFlag(w io.Writer, fs *flag.FlagSet, fl *flag.Flag, indent string) {
+	fl = unwrapFlag(fl)
+	names := "-" + fl.Name
+	f := lookup(fs, fl.Name)
+	if f != nil && f.short != "" {
+		names = "-" + f.short + ", " + names
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "  %s", names)
+	name, usage := flag.UnquoteUsage(fl)
+	if f != nil && f.placeholder != "" {
+		name = f.placeholder
+	}
+	if len(name) > 0 {
+		b.WriteString(" ")
+		b.WriteString(name)
+	}
+	// 与 flag 包相同，单个字母的布尔标志将用法信息放在同一行。
+	if b.Len() <= 4 {
+		b.WriteString("\t")
+	} else {
+		b.WriteString("\n" + indent + "    \t")
+	}
+	b.WriteString(strings.ReplaceAll(usage, "\n", "\n"+indent+"    \t"))
+	if f != nil && len(f.aliases) > 0 {
+		fmt.Fprintf(&b, " (%s -%s)", label(f, "aliases:"), strings.Join(f.aliases, ", -"))
+	}
+	if f != nil && f.renamedFrom != "" {
+		fmt.Fprintf(&b, " (%s -%s)", label(f, "formerly"), f.renamedFrom)
+	}
+	if f != nil && len(f.derived) > 0 {
+		names := make([]string, len(f.derived))
+		for i, d := range f.derived {
+			names[i] = d.name
+		}
+		fmt.Fprintf(&b, " (%s -%s)", label(f, "derived:"), strings.Join(names, ", -"))
+	}
+	if f != nil && f.env != "" {
+		fmt.Fprintf(&b, " (%s $%s)", label(f, "env"), f.env)
+	}
+	if def := defaultText(f, fl); def != "" {
+		b.WriteString(" " + def)
+	}
+	fmt.Fprint(w, indent, b.String(), "\n")
+}
+
+// defaultText 返回用法信息中标志 fl 的默认值部分，例如 "(default 8080)"。f 为 fl 对应的字段，可以为 nil。
+// 默认值被 "show-default" 隐藏或为零值时返回空字符串。
+func defaultText(f *field, fl *flag.Flag) string {
+	switch {
+	case f != nil && f.hideDefault:
+	case f != nil && (f.mask != "" || f.sensitive) && !(f.mask == "" && isZeroValue(fl)):
+		return fmt.Sprintf("(%s %s)", label(f, "default"), f.displayDefault(fl))
+	case !isZeroValue(fl):
+		if t := reflect.TypeOf(fl.Value); t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.String {
+			return fmt.Sprintf("(%s %q)", label(f, "default"), fl.DefValue)
+		}
+		return fmt.Sprintf("(%s %v)", label(f, "default"), fl.DefValue)
+	}
+	return ""
+}
+
+// FormatGetopt 返回 v 生成的标志的 getopt 风格的用法信息，每个标志一行，例如：
+//
+//	-p, --port <int>      listen port (default 8080)
+//	    --host <string>   listen host
+//
+// 短选项与长名称显示在同一行，尖括号中为参数名称：优先使用 "placeholder" 标签，其次是用法信息中的反引号，
+// 否则为字段的类型，布尔标志没有参数名称。用法信息按照列对齐，隐藏的和已弃用的标志不会输出。
+//
+// v 必须是此前通过 LoadTo 加载到 fs 上的结构体指针。
+func FormatGetopt(fs *flag.FlagSet, v interface{}) string {
+	var lefts, rights []string
+	hasShort := false
+	for _, f := range fieldsOf(fs, v) {
+		if f.short != "" && !f.hidden && f.deprecated == "" {
+			hasShort = true
+		}
+	}
+	width := 0
+	for _, f := range fieldsOf(fs, v) {
+		fl := fs.Lookup(f.name)
+		if fl == nil || f.hidden || f.deprecated != "" {
+			continue
+		}
+		fl = unwrapFlag(fl)
+		name, usage := flag.UnquoteUsage(fl)
+		if f.placeholder != "" {
+			name = f.placeholder
+		} else if name == "value" {
+			name = argName(f.typ)
+		}
+
+		var left strings.Builder
+		switch {
+		case f.short != "":
+			fmt.Fprintf(&left, "  -%s, --%s", f.short, f.name)
+		case hasShort:
+			fmt.Fprintf(&left, "      --%s", f.name)
+		default:
+			fmt.Fprintf(&left, "  --%s", f.name)
+		}
+		if name != "" {
+			fmt.Fprintf(&left, " <%s>", name)
+		}
+		if def := defaultText(f, fl); def != "" {
+			usage = strings.TrimSpace(usage + " " + def)
+		}
+		lefts = append(lefts, left.String())
+		rights = append(rights, usage)
+		if left.Len() > width {
+			width = left.Len()
+		}
+	}
+
+	var b strings.Builder
+	for i, left := range lefts {
+		if rights[i] == "" {
+			b.WriteString(left + "\n")
+			continue
+		}
+		pad := strings.Repeat(" ", width+3)
+		fmt.Fprintf(&b, "%-*s   %s\n", width, left, strings.ReplaceAll(rights[i], "\n", "\n"+pad))
+	}
+	return b.String()
+}
+
+// argName 返回 FormatGetopt 中类型为 t 的字段的参数名称，例如 "int"、"duration" 和 "[]string" 的 "string"。
+func argName(t reflect.Type) string {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+	switch {
+	case t == reflect.TypeOf(time.Duration(0)):
+		return "duration"
+	case t == reflect.TypeOf(time.Time{}):
+		return "time"
+	case t.Name() != "":
+		return strings.ToLower(t.Name())
+	}
+	return "value"
+}
+
+// isZeroValue 判断标志的默认值是否为其类型的零值，与 flag 包的同名函数相同。
+// 零值的 String 方法引发 panic 时（例如不处理 nil 接收者的指针类型）视为非零值，以便照常显示默认值。
+func isZeroValue(fl *flag.Flag) (zero bool) {
+	defer func() {
+		if recover() != nil {
+			zero = false
+		}
+	}()
+	if z, ok := fl.Value.(zeroStringer); ok {
+		return fl.DefValue == z.zeroString()
+	}
+	typ := reflect.TypeOf(fl.Value)
+	var z reflect.Value
+	if typ.Kind() == reflect.Ptr {
+		z = reflect.New(typ.Elem())
+	} else {
+		z = reflect.Zero(typ)
+	}
+	return fl.DefValue == z.Interface().(flag.Value).String()
+}
@@ -0,0 +1,54 @@
+package structflag
+
+import (
+	"flag"
+	"strings"
+	"testing"
+)
+
+func TestUsageOptIn(t *testing.T) {
+	type config struct {
+		Verbose bool `flag:"verbose" short:"v"`
+	}
+	var cfg config
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	var buf strings.Builder
+	fs.SetOutput(&buf)
+	fs.Usage = func() { buf.WriteString("custom usage") }
+	if err := TryLoadTo(fs, "", &cfg); err != nil {
+		t.Fatalf("TryLoadTo: %v", err)
+	}
+	fs.Usage()
+	if got := buf.String(); got != "custom usage" {
+		t.Errorf("usage without WithUsage = %q, want the custom usage", got)
+	}
+
+	fs = flag.NewFlagSet("test", flag.ContinueOnError)
+	buf.Reset()
+	fs.SetOutput(&buf)
+	fs.Usage = func() { buf.WriteString("custom usage") }
+	if err := TryLoadTo(fs, "", &cfg, WithUsage()); err != nil {
+		t.Fatalf("TryLoadTo: %v", err)
+	}
+	fs.Usage()
+	if got := buf.String(); !strings.Contains(got, "-v, -verbose") {
+		t.Errorf("usage with WithUsage = %q, want the short option next to the long name", got)
+	}
+}
+
+// nilStringValue 的 String 方法不处理 nil 接收者。
+type nilStringValue struct{ s string }
+
+func (v *nilStringValue) String() string     { return v.s }
+func (v *nilStringValue) Set(s string) error { v.s = s; return nil }
+
+func TestPrintDefaultsZeroValuePanic(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	var buf strings.Builder
+	fs.SetOutput(&buf)
+	fs.Var(&nilStringValue{s: "x"}, "value", "a value")
+	PrintDefaults(fs)
+	if got := buf.String(); !strings.Contains(got, "(default x)") {
+		t.Errorf("PrintDefaults = %q, want the default value", got)
+	}
+}
@@ -0,0 +1,59 @@
+package structflag
+
+import (
+	"flag"
+	"reflect"
+)
+
+// Validator 由需要在解析之后检查自身的结构体实现，例如检查多个字段之间的关系。
+type Validator interface {
+	Validate() error
+}
+
+// ParseAndValidate 调用 ApplyEnv 应用环境变量，在 v 使用 WithResponseFiles 加载时展开 args 中的
+// 响应文件，并使用 args 解析 fs，然后检查 v 及其嵌套结构体。
+//
+// 如果 v 或其中的嵌套结构体字段实现了 Validator，则调用其 Validate 方法；嵌套结构体先于
+// 包含它们的结构体检查。所有 Validate 返回的错误都会被报告：只有一个错误时原样返回，
+// 否则使用 errors.Join 合并。解析失败时直接返回解析错误，不会进行检查。
+func ParseAndValidate(fs *flag.FlagSet, args []string, v interface{}) error {
+	if err := ApplyEnv(fs); err != nil {
+		return err
+	}
+	args, err := parseArgs(fs, loadOptions(fs, v), args)
+	if err != nil {
+		return err
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	var errs []error
+	validate(reflect.ValueOf(v), &errs)
+	return joinErrors(errs)
+}
+
+// validate 递归检查 v 中的嵌套结构体，然后检查 v 本身，并将错误追加到 errs。
+func validate(v reflect.Value, errs *[]error) {
+	s := v
+	if s.Kind() == reflect.Ptr {
+		if s.IsNil() {
+			return
+		}
+		s = s.Elem()
+	}
+	if s.Kind() == reflect.Struct {
+		for i := 0; i < s.NumField(); i++ {
+			if f := s.Field(i); f.Kind() == reflect.Struct && f.CanAddr() && f.Addr().CanInterface() {
+				validate(f.Addr(), errs)
+			}
+		}
+	}
+	if !v.CanInterface() {
+		return
+	}
+	if val, ok := v.Interface().(Validator); ok {
+		if err := val.Validate(); err != nil {
+			*errs = append(*errs, err)
+		}
+	}
+}
@@ -0,0 +1,655 @@
+package structflag
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"math/big"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// newValue 为 flag 包不原生支持的字段类型，或者通过 RegisterParser 注册了解析函数的类型，
+// 返回一个绑定到该字段的 flag.Value。如果字段类型不在此列，则返回 nil。
+func newValue(field reflect.Value) flag.Value {
+	if !field.CanInterface() {
+		return nil
+	}
+	if parse := lookupParser(field.Type()); parse != nil {
+		return &parserValue{field: field, parse: parse}
+	}
+	if m, ok := lookupFactory(field.Type()); ok {
+		return &factoryValue{field: field, m: m}
+	}
+	switch p := field.Addr().Interface().(type) {
+	case *url.URL:
+		return (*urlValue)(p)
+	case **url.URL:
+		return &urlPtrValue{p: p}
+	case *big.Int:
+		return (*bigIntValue)(p)
+	case **big.Int:
+		return &bigIntPtrValue{p: p}
+	case *big.Float:
+		return (*bigFloatValue)(p)
+	case **big.Float:
+		return &bigFloatPtrValue{p: p}
+	case *time.Time:
+		return &timeValue{p: p, layout: time.RFC3339}
+	}
+	if field.Kind() == reflect.Ptr && isScalar(field.Type().Elem()) {
+		return &scalarPtrValue{field: field}
+	}
+	if field.Kind() == reflect.Slice && isScalar(field.Type().Elem()) {
+		return &sliceValue{field: field}
+	}
+	if field.Kind() == reflect.Array && isScalar(field.Type().Elem()) {
+		return &arrayValue{field: field}
+	}
+	if isScalar(field.Type()) && !isNative(field.Type()) {
+		return &scalarValue{field: field}
+	}
+	return nil
+}
+
+// isNative 报告 t 是否为 flag 包原生支持的类型。
+func isNative(t reflect.Type) bool {
+	switch t {
+	case reflect.TypeOf(false), reflect.TypeOf(""), reflect.TypeOf(0), reflect.TypeOf(int64(0)),
+		reflect.TypeOf(uint(0)), reflect.TypeOf(uint64(0)), reflect.TypeOf(0.0), durationType:
+		return true
+	}
+	return false
+}
+
+// zeroStringer 由无法通过零值的 String 方法得到字段零值表示的 flag.Value 实现，
+// 用于在打印用法信息时判断默认值是否为零值。
+type zeroStringer interface {
+	zeroString() string
+}
+
+// defaultSetter 由解析 "default" 标签的方式与命令行参数不同的 flag.Value 实现，
+// 例如整数总是按照 parseDefault 以十进制解析。
+type defaultSetter interface {
+	SetDefault(s string) error
+}
+
+// urlValue 将 url.URL 字段实现为 flag.Value。
+type urlValue url.URL
+
+func (v *urlValue) Set(s string) error {
+	u, err := url.Parse(s)
+	if err != nil {
+		return err
+	}
+	*v = urlValue(*u)
+	return nil
+}
+
+func (v *urlValue) String() string {
+	if v == nil {
+		return ""
+	}
+	return (*url.URL)(v).String()
+}
+
+// urlPtrValue 将 *url.URL 字段实现为 flag.Value，在设置时分配新的 url.URL。
+type urlPtrValue struct {
+	p **url.URL
+}
+
+func (v *urlPtrValue) Set(s string) error {
+	u, err := url.Parse(s)
+	if err != nil {
+		return err
+	}
+	*v.p = u
+	return nil
+}
+
+// SetDefault 与 Set 相同，但空字符串会将字段重置为 nil。
+func (v *urlPtrValue) SetDefault(s string) error {
+	if s == "" {
+		*v.p = nil
+		return nil
+	}
+	return v.Set(s)
+}
+
+func (v *urlPtrValue) String() string {
+	if v.p == nil || *v.p == nil {
+		return ""
+	}
+	return (*v.p).String()
+}
+
+// parseBigInt 解析任意精度的整数，支持 0x、0o、0b 等前缀。
+func parseBigInt(s string) (*big.Int, error) {
+	n, ok := new(big.Int).SetString(s, 0)
+	if !ok {
+		return nil, errors.New("invalid integer")
+	}
+	return n, nil
+}
+
+// bigIntValue 将 big.Int 字段实现为 flag.Value。
+type bigIntValue big.Int
+
+func (v *bigIntValue) Set(s string) error {
+	n, err := parseBigInt(s)
+	if err != nil {
+		return err
+	}
+	(*big.Int)(v).Set(n)
+	return nil
+}
+
+func (v *bigIntValue) String() string {
+	if v == nil {
+		return ""
+	}
+	return (*big.Int)(v).String()
+}
+
+// bigIntPtrValue 将 *big.Int 字段实现为 flag.Value，在设置时分配新的 big.Int。
+type bigIntPtrValue struct {
+	p **big.Int
+}
+
+func (v *bigIntPtrValue) Set(s string) error {
+	n, err := parseBigInt(s)
+	if err != nil {
+		return err
+	}
+	*v.p = n
+	return nil
+}
+
+// SetDefault 与 Set 相同，但空字符串会将字段重置为 nil。
+func (v *bigIntPtrValue) SetDefault(s string) error {
+	if s == "" {
+		*v.p = nil
+		return nil
+	}
+	return v.Set(s)
+}
+
+func (v *bigIntPtrValue) String() string {
+	if v.p == nil || *v.p == nil {
+		return ""
+	}
+	return (*v.p).String()
+}
+
+// parseBigFloat 以精度 prec 解析任意精度的浮点数。prec 为 0 时使用 big.Float 的默认精度 64。
+func parseBigFloat(s string, prec uint) (*big.Float, error) {
+	f, ok := new(big.Float).SetPrec(prec).SetString(s)
+	if !ok {
+		return nil, errors.New("invalid number")
+	}
+	return f, nil
+}
+
+// bigFloatValue 将 big.Float 字段实现为 flag.Value，解析时保留字段原有的精度。
+type bigFloatValue big.Float
+
+func (v *bigFloatValue) Set(s string) error {
+	f, err := parseBigFloat(s, (*big.Float)(v).Prec())
+	if err != nil {
+		return err
+	}
+	(*big.Float)(v).Set(f)
+	return nil
+}
+
+func (v *bigFloatValue) String() string {
+	if v == nil {
+		return ""
+	}
+	return (*big.Float)(v).Text('g', -1)
+}
+
+// bigFloatPtrValue 将 *big.Float 字段实现为 flag.Value，在设置时分配新的 big.Float。
+// 如果字段已有值，则新值沿用其精度。
+type bigFloatPtrValue struct {
+	p **big.Float
+}
+
+func (v *bigFloatPtrValue) Set(s string) error {
+	var prec uint
+	if *v.p != nil {
+		prec = (*v.p).Prec()
+	}
+	f, err := parseBigFloat(s, prec)
+	if err != nil {
+		return err
+	}
+	*v.p = f
+	return nil
+}
+
+// SetDefault 与 Set 相同，但空字符串会将字段重置为 nil。
+func (v *bigFloatPtrValue) SetDefault(s string) error {
+	if s == "" {
+		*v.p = nil
+		return nil
+	}
+	return v.Set(s)
+}
+
+func (v *bigFloatPtrValue) String() string {
+	if v.p == nil || *v.p == nil {
+		return ""
+	}
+	return (*v.p).Text('g', -1)
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// isScalar 报告 t 是否为可以由 parseScalar 解析的基本类型。
+func isScalar(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// parseScalar 将 s 解析为基本类型 t 的值。time.Duration 使用 time.ParseDuration 解析。
+func parseScalar(t reflect.Type, s string) (reflect.Value, error) {
+	v := reflect.New(t).Elem()
+	if t == durationType {
+		// time.ParseDuration 的错误信息说明了具体原因，例如缺少单位，因此原样返回。
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return v, err
+		}
+		v.SetInt(int64(d))
+		return v, nil
+	}
+	var err error
+	switch t.Kind() {
+	case reflect.Bool:
+		var b bool
+		b, err = strconv.ParseBool(s)
+		v.SetBool(b)
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		n, err = strconv.ParseInt(s, 0, t.Bits())
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var n uint64
+		n, err = strconv.ParseUint(s, 0, t.Bits())
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		f, err = strconv.ParseFloat(s, t.Bits())
+		v.SetFloat(f)
+	}
+	if err != nil {
+		if ne, ok := err.(*strconv.NumError); ok {
+			err = ne.Err
+		}
+		return v, fmt.Errorf("invalid %s: %v", t, err)
+	}
+	return v, nil
+}
+
+// parseDefault 与 parseScalar 相同，但整数总是按照十进制解析，因此 "default" 标签中的 "010" 为 10 而不是 8。
+func parseDefault(t reflect.Type, s string) (reflect.Value, error) {
+	if t == durationType {
+		return parseScalar(t, s)
+	}
+	v := reflect.New(t).Elem()
+	var err error
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		n, err = strconv.ParseInt(s, 10, t.Bits())
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var n uint64
+		n, err = strconv.ParseUint(s, 10, t.Bits())
+		v.SetUint(n)
+	default:
+		return parseScalar(t, s)
+	}
+	if err != nil {
+		if ne, ok := err.(*strconv.NumError); ok {
+			err = ne.Err
+		}
+		return v, fmt.Errorf("invalid %s: %v", t, err)
+	}
+	return v, nil
+}
+
+// formatScalar 按照命令行参数的格式返回基本类型的值。
+func formatScalar(v reflect.Value) string {
+	if v.Type() == durationType {
+		return time.Duration(v.Int()).String()
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// sliceValue 将元素为基本类型的切片字段实现为 flag.Value。
+//
+// 每次设置标志都会追加一个元素，但第一次设置会替换默认值而不是追加到默认值之后。
+// "default" 标签使用逗号分隔多个元素。
+type sliceValue struct {
+	field   reflect.Value
+	changed bool
+}
+
+func (v *sliceValue) Set(s string) error {
+	elem, err := parseScalar(v.field.Type().Elem(), s)
+	if err != nil {
+		return err
+	}
+	if !v.changed {
+		v.field.Set(reflect.MakeSlice(v.field.Type(), 0, 1))
+		v.changed = true
+	}
+	v.field.Set(reflect.Append(v.field, elem))
+	return nil
+}
+
+// SetDefault 将字段设为 s 中以逗号分隔的元素，整数元素与 "default" 标签相同按照十进制解析。
+func (v *sliceValue) SetDefault(s string) error {
+	slice := reflect.MakeSlice(v.field.Type(), 0, 0)
+	if s != "" {
+		for i, tok := range strings.Split(s, ",") {
+			elem, err := parseDefault(v.field.Type().Elem(), strings.TrimSpace(tok))
+			if err != nil {
+				return fmt.Errorf("element %d %q: %v", i, tok, err)
+			}
+			slice = reflect.Append(slice, elem)
+		}
+	}
+	v.field.Set(slice)
+	v.changed = false
+	return nil
+}
+
+func (v *sliceValue) String() string {
+	if !v.field.IsValid() {
+		return ""
+	}
+	elems := make([]string, v.field.Len())
+	for i := range elems {
+		elems[i] = formatScalar(v.field.Index(i))
+	}
+	return strings.Join(elems, ",")
+}
+
+// arrayValue 将元素为基本类型的数组字段实现为 flag.Value，例如 [3]uint8。
+//
+// 标志的值为以逗号或空白分隔的元素列表，例如 "255,128,0"，元素个数必须与数组的长度相同。
+type arrayValue struct {
+	field reflect.Value
+}
+
+func (v *arrayValue) Set(s string) error {
+	return v.set(s, parseScalar)
+}
+
+// SetDefault 与 Set 相同，但整数元素按照十进制解析。
+func (v *arrayValue) SetDefault(s string) error {
+	return v.set(s, parseDefault)
+}
+
+// set 使用 parse 解析 s 中的每个元素并设置到字段。
+func (v *arrayValue) set(s string, parse func(reflect.Type, string) (reflect.Value, error)) error {
+	toks := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+	if len(toks) != v.field.Len() {
+		return fmt.Errorf("expected %d elements, got %d", v.field.Len(), len(toks))
+	}
+	array := reflect.New(v.field.Type()).Elem()
+	for i, tok := range toks {
+		elem, err := parse(v.field.Type().Elem(), tok)
+		if err != nil {
+			return fmt.Errorf("element %d %q: %v", i, tok, err)
+		}
+		array.Index(i).Set(elem)
+	}
+	v.field.Set(array)
+	return nil
+}
+
+func (v *arrayValue) String() string {
+	if !v.field.IsValid() {
+		return ""
+	}
+	return formatArray(v.field)
+}
+
+func (v *arrayValue) zeroString() string {
+	return formatArray(reflect.Zero(v.field.Type()))
+}
+
+// formatArray 将数组的元素格式化为以逗号分隔的列表。
+func formatArray(v reflect.Value) string {
+	elems := make([]string, v.Len())
+	for i := range elems {
+		elems[i] = formatScalar(v.Index(i))
+	}
+	return strings.Join(elems, ",")
+}
+
+// scalarValue 将 flag 包不原生支持的基本类型字段实现为 flag.Value，
+// 例如 int8、float32 以及 `type Count int` 这样的具名类型。
+type scalarValue struct {
+	field reflect.Value
+}
+
+func (v *scalarValue) Set(s string) error {
+	x, err := parseScalar(v.field.Type(), s)
+	if err != nil {
+		return err
+	}
+	v.field.Set(x)
+	return nil
+}
+
+// SetDefault 与 Set 相同，但整数按照十进制解析。
+func (v *scalarValue) SetDefault(s string) error {
+	x, err := parseDefault(v.field.Type(), s)
+	if err != nil {
+		return err
+	}
+	v.field.Set(x)
+	return nil
+}
+
+func (v *scalarValue) String() string {
+	if !v.field.IsValid() {
+		return ""
+	}
+	return formatScalar(v.field)
+}
+
+func (v *scalarValue) IsBoolFlag() bool {
+	return v.field.Kind() == reflect.Bool
+}
+
+func (v *scalarValue) zeroString() string {
+	return formatScalar(reflect.Zero(v.field.Type()))
+}
+
+// scalarPtrValue 将指向基本类型的指针字段实现为 flag.Value，例如 *int 和 *time.Duration。
+// 设置标志时会分配新值，因此未设置的字段保持为 nil，可以与显式设置的零值区分开来。
+type scalarPtrValue struct {
+	field reflect.Value
+}
+
+func (v *scalarPtrValue) Set(s string) error {
+	return v.set(s, parseScalar)
+}
+
+// SetDefault 与 Set 相同，但整数按照十进制解析，空字符串会将字段重置为 nil。
+func (v *scalarPtrValue) SetDefault(s string) error {
+	if s == "" {
+		v.field.Set(reflect.Zero(v.field.Type()))
+		return nil
+	}
+	return v.set(s, parseDefault)
+}
+
+// set 使用 parse 解析 s，并将字段设为指向新值的指针。
+func (v *scalarPtrValue) set(s string, parse func(reflect.Type, string) (reflect.Value, error)) error {
+	x, err := parse(v.field.Type().Elem(), s)
+	if err != nil {
+		return err
+	}
+	p := reflect.New(v.field.Type().Elem())
+	p.Elem().Set(x)
+	v.field.Set(p)
+	return nil
+}
+
+func (v *scalarPtrValue) String() string {
+	if !v.field.IsValid() || v.field.IsNil() {
+		return ""
+	}
+	return formatScalar(v.field.Elem())
+}
+
+func (v *scalarPtrValue) IsBoolFlag() bool {
+	return v.field.Type().Elem().Kind() == reflect.Bool
+}
+
+func (v *scalarPtrValue) zeroString() string {
+	return ""
+}
+
+// isInteger 报告 t 是否为整数类型（time.Duration 除外）。
+func isInteger(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return t != durationType
+	}
+	return false
+}
+
+// countValue 将带有 `count:"true"` 标签的整数字段实现为计数标志。
+//
+// 它实现了 IsBoolFlag，因此 "-v" 不需要值，每次出现时字段加一；"-v=n" 将字段直接设为 n。
+// flag 包为 "-v" 传入的值为 "true"，因此 "-v=true" 与 "-v" 相同，同样加一；"-v=false" 将计数清零。
+type countValue struct {
+	field reflect.Value
+}
+
+func (v *countValue) Set(s string) error {
+	switch s {
+	case "false":
+		v.field.Set(reflect.Zero(v.field.Type()))
+		return nil
+	case "true":
+		switch v.field.Kind() {
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			v.field.SetUint(v.field.Uint() + 1)
+		default:
+			v.field.SetInt(v.field.Int() + 1)
+		}
+		return nil
+	}
+	x, err := parseScalar(v.field.Type(), s)
+	if err != nil {
+		return err
+	}
+	v.field.Set(x)
+	return nil
+}
+
+// SetDefault 将字段设为 s 表示的计数，而不是加一。整数按照十进制解析。
+func (v *countValue) SetDefault(s string) error {
+	x, err := parseDefault(v.field.Type(), s)
+	if err != nil {
+		return err
+	}
+	v.field.Set(x)
+	return nil
+}
+
+func (v *countValue) String() string {
+	if !v.field.IsValid() {
+		return ""
+	}
+	return formatScalar(v.field)
+}
+
+func (v *countValue) IsBoolFlag() bool {
+	return true
+}
+
+func (v *countValue) zeroString() string {
+	return formatScalar(reflect.Zero(v.field.Type()))
+}
+
+// timeValue 将 time.Time 字段实现为 flag.Value，命令行参数按照 layout 解析，默认为 time.RFC3339。
+type timeValue struct {
+	p      *time.Time
+	layout string
+}
+
+func (v *timeValue) Set(s string) error {
+	t, err := time.Parse(v.layout, s)
+	if err != nil {
+		return err
+	}
+	*v.p = t
+	return nil
+}
+
+// SetDefault 除了 layout 之外，还接受 "now" 和 "now+1h"、"now-30m" 等相对于加载时刻的表达式，
+// 以及 time.RFC3339、time.DateTime 和 time.DateOnly 格式的绝对时间。空字符串将字段设为零值。
+func (v *timeValue) SetDefault(s string) error {
+	if s == "" {
+		*v.p = time.Time{}
+		return nil
+	}
+	if rest, ok := strings.CutPrefix(s, "now"); ok {
+		t := time.Now()
+		if rest != "" {
+			if rest[0] != '+' && rest[0] != '-' {
+				return fmt.Errorf("invalid time expression %q: want now, now+duration or now-duration", s)
+			}
+			d, err := time.ParseDuration(rest)
+			if err != nil {
+				return fmt.Errorf("invalid time expression %q: %v", s, err)
+			}
+			t = t.Add(d)
+		}
+		*v.p = t
+		return nil
+	}
+	for _, layout := range []string{v.layout, time.RFC3339, time.DateTime, time.DateOnly} {
+		if t, err := time.Parse(layout, s); err == nil {
+			*v.p = t
+			return nil
+		}
+	}
+	return fmt.Errorf("cannot parse %q as time with layout %q", s, v.layout)
+}
+
+func (v *timeValue) String() string {
+	if v.p == nil || v.p.IsZero() {
+		return ""
+	}
+	return v.p.Format(v.layout)
+}
+
+func (v *timeValue) zeroString() string {
+	return ""
+}
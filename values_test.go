@@ -0,0 +1,295 @@
+package structflag
+
+import (
+	"flag"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPointerTagParity(t *testing.T) {
+	tests := []struct {
+		typ      reflect.Type
+		def, arg string
+	}{
+		{reflect.TypeOf(false), "true", "false"},
+		{reflect.TypeOf(""), "a", "b"},
+		{reflect.TypeOf(0), "5", "6"},
+		{reflect.TypeOf(int8(0)), "5", "6"},
+		{reflect.TypeOf(int64(0)), "5", "6"},
+		{reflect.TypeOf(uint(0)), "5", "6"},
+		{reflect.TypeOf(uint32(0)), "5", "6"},
+		{reflect.TypeOf(uint64(0)), "5", "6"},
+		{reflect.TypeOf(float32(0)), "1.5", "2.5"},
+		{reflect.TypeOf(0.0), "1.5", "2.5"},
+		{reflect.TypeOf(time.Duration(0)), "1s", "2s"},
+	}
+	for _, tt := range tests {
+		tag := reflect.StructTag(`flag:"n" default:"` + tt.def + `" short:"x" usage:"count"`)
+		load := func(typ reflect.Type) (*flag.FlagSet, reflect.Value) {
+			v := reflect.New(reflect.StructOf([]reflect.StructField{{Name: "N", Type: typ, Tag: tag}}))
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			if err := TryLoadTo(fs, "", v.Interface()); err != nil {
+				t.Fatalf("%s: TryLoadTo: %v", typ, err)
+			}
+			return fs, v.Elem().Field(0)
+		}
+		vfs, val := load(tt.typ)
+		pfs, ptr := load(reflect.PtrTo(tt.typ))
+
+		for _, name := range []string{"n", "x"} {
+			vf, pf := vfs.Lookup(name), pfs.Lookup(name)
+			if pf == nil {
+				t.Errorf("%s: flag %s is not registered for the pointer field", tt.typ, name)
+				continue
+			}
+			if vf.DefValue != pf.DefValue || vf.Usage != pf.Usage {
+				t.Errorf("%s: flag %s = (%q, %q) for the pointer field, want (%q, %q)", tt.typ, name, pf.DefValue, pf.Usage, vf.DefValue, vf.Usage)
+			}
+		}
+		if ptr.IsNil() || ptr.Elem().Interface() != val.Interface() {
+			t.Errorf("%s: pointer field = %v, want %v", tt.typ, ptr, val)
+		}
+
+		if err := vfs.Parse([]string{"-x=" + tt.arg}); err != nil {
+			t.Fatalf("%s: Parse: %v", tt.typ, err)
+		}
+		if err := pfs.Parse([]string{"-x=" + tt.arg}); err != nil {
+			t.Fatalf("%s: Parse: %v", tt.typ, err)
+		}
+		if ptr.IsNil() || ptr.Elem().Interface() != val.Interface() {
+			t.Errorf("%s: pointer field after parsing = %v, want %v", tt.typ, ptr, val)
+		}
+	}
+
+	// 没有 "default" 标签且未设置的指针字段保持为 nil。
+	var cfg struct {
+		N *int `flag:"n"`
+	}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	if err := TryLoadTo(fs, "", &cfg); err != nil {
+		t.Fatalf("TryLoadTo: %v", err)
+	}
+	if err := fs.Parse(nil); err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if cfg.N != nil {
+		t.Errorf("N = %v, want nil", *cfg.N)
+	}
+}
+
+func TestNegativeDefaults(t *testing.T) {
+	var cfg struct {
+		Offset  time.Duration `flag:"offset" default:"-30m"`
+		Delta   int           `flag:"delta" default:"-5"`
+		Delta64 int64         `flag:"delta64" default:"-5"`
+		Scale   float64       `flag:"scale" default:"-1.5"`
+	}
+	if err := TryLoadTo(flag.NewFlagSet("test", flag.ContinueOnError), "", &cfg); err != nil {
+		t.Fatalf("TryLoadTo: %v", err)
+	}
+	if cfg.Offset != -30*time.Minute || cfg.Delta != -5 || cfg.Delta64 != -5 || cfg.Scale != -1.5 {
+		t.Errorf("cfg = %+v", cfg)
+	}
+}
+
+func TestInvalidNumericDefaults(t *testing.T) {
+	tests := []struct {
+		v   interface{}
+		err string
+	}{
+		{&struct {
+			N float64 `default:"-x"`
+		}{}, "invalid float64"},
+		{&struct {
+			N int64 `default:"-"`
+		}{}, "invalid int64"},
+	}
+	for _, tt := range tests {
+		err := TryLoadTo(flag.NewFlagSet("test", flag.ContinueOnError), "", tt.v)
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("TryLoadTo(%T) = %v, want an error containing %q", tt.v, err, tt.err)
+		}
+	}
+}
+
+func TestDurationDefaults(t *testing.T) {
+	for _, def := range []string{"", "abc"} {
+		v := reflect.New(reflect.StructOf([]reflect.StructField{{
+			Name: "Timeout",
+			Type: reflect.TypeOf(time.Duration(0)),
+			Tag:  reflect.StructTag(`flag:"timeout" default:"` + def + `"`),
+		}}))
+		err := TryLoadTo(flag.NewFlagSet("test", flag.ContinueOnError), "", v.Interface())
+		want := `structflag: invalid default "` + def + `" for field`
+		if err == nil || !strings.Contains(err.Error(), want) || !strings.Contains(err.Error(), ".Timeout") {
+			t.Errorf("default %q: TryLoadTo = %v, want an error containing %q", def, err, want)
+		}
+	}
+
+	cfg := struct {
+		Timeout  time.Duration `flag:"timeout" default:"1h30m"`
+		Interval time.Duration `flag:"interval"`
+	}{Interval: 5 * time.Second}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	if err := TryLoadTo(fs, "", &cfg); err != nil {
+		t.Fatalf("TryLoadTo: %v", err)
+	}
+	if cfg.Timeout != 90*time.Minute || cfg.Interval != 5*time.Second {
+		t.Errorf("cfg = %+v", cfg)
+	}
+	if got := fs.Lookup("interval").DefValue; got != "5s" {
+		t.Errorf("default of interval = %q, want %q", got, "5s")
+	}
+}
+
+func TestIntegerDefaultRange(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+		err  string
+	}{
+		{"int max", &struct {
+			N int `default:"9223372036854775807"`
+		}{}, ""},
+		{"int max+1", &struct {
+			N int `default:"9223372036854775808"`
+		}{}, "value out of range"},
+		{"int64 min", &struct {
+			N int64 `default:"-9223372036854775808"`
+		}{}, ""},
+		{"int64 min-1", &struct {
+			N int64 `default:"-9223372036854775809"`
+		}{}, "value out of range"},
+		{"uint64 max", &struct {
+			N uint64 `default:"18446744073709551615"`
+		}{}, ""},
+		{"uint64 max+1", &struct {
+			N uint64 `default:"18446744073709551616"`
+		}{}, "value out of range"},
+		{"uint negative", &struct {
+			N uint `default:"-1"`
+		}{}, "invalid syntax"},
+		{"bool", &struct {
+			N bool `default:"1"`
+		}{}, ""},
+		{"bool invalid", &struct {
+			N bool `default:"yes"`
+		}{}, "invalid bool"},
+	}
+	if strconv.IntSize == 64 {
+		tests = append(tests, struct {
+			name string
+			v    interface{}
+			err  string
+		}{"uint max", &struct {
+			N uint `default:"18446744073709551615"`
+		}{}, ""})
+	}
+	for _, tt := range tests {
+		err := TryLoadTo(flag.NewFlagSet("test", flag.ContinueOnError), "", tt.v)
+		switch {
+		case tt.err == "" && err != nil:
+			t.Errorf("%s: TryLoadTo: %v", tt.name, err)
+		case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err) || !strings.Contains(err.Error(), ".N")):
+			t.Errorf("%s: TryLoadTo = %v, want an error containing %q and the field path", tt.name, err, tt.err)
+		}
+	}
+}
+
+func TestDefaultParsing(t *testing.T) {
+	var cfg struct {
+		Int     int     `flag:"int" default:"010"`
+		Uint64  uint64  `flag:"uint64" default:"010"`
+		Bool    bool    `flag:"bool" default:"TRUE"`
+		False   bool    `flag:"false" default:"false"`
+		Float64 float64 `flag:"float64" default:"1.5"`
+	}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	if err := TryLoadTo(fs, "", &cfg); err != nil {
+		t.Fatalf("TryLoadTo: %v", err)
+	}
+	if cfg.Int != 10 || cfg.Uint64 != 10 || !cfg.Bool || cfg.False || cfg.Float64 != 1.5 {
+		t.Errorf("cfg = %+v", cfg)
+	}
+
+	// 命令行参数与 flag 包相同，接受 0x 等前缀。
+	if err := fs.Parse([]string{"-int", "0x10"}); err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if cfg.Int != 16 {
+		t.Errorf("Int = %d, want 16", cfg.Int)
+	}
+}
+
+func TestDecimalDefaultShapes(t *testing.T) {
+	type config struct {
+		Scalar  int8     `flag:"scalar" default:"010"`
+		Pointer *int     `flag:"pointer" default:"010"`
+		Slice   []int    `flag:"slice" default:"08,09"`
+		Array   [2]uint  `flag:"array" default:"08,010"`
+		Count   int      `flag:"count" count:"true" default:"010"`
+		Strings []string `flag:"strings" default:"010"`
+	}
+	tests := []struct {
+		name string
+		got  func(c *config) interface{}
+		want interface{}
+	}{
+		{"scalar", func(c *config) interface{} { return c.Scalar }, int8(10)},
+		{"pointer", func(c *config) interface{} { return *c.Pointer }, 10},
+		{"slice", func(c *config) interface{} { return c.Slice }, []int{8, 9}},
+		{"array", func(c *config) interface{} { return c.Array }, [2]uint{8, 10}},
+		{"count", func(c *config) interface{} { return c.Count }, 10},
+		{"strings", func(c *config) interface{} { return c.Strings }, []string{"010"}},
+	}
+	var cfg config
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	if err := TryLoadTo(fs, "", &cfg); err != nil {
+		t.Fatalf("TryLoadTo: %v", err)
+	}
+	for _, tt := range tests {
+		if got := tt.got(&cfg); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	// 命令行参数仍接受 0x 等前缀。
+	if err := fs.Parse([]string{"-scalar", "0x10", "-pointer", "0x10", "-slice", "0x10", "-array", "0x10,010"}); err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if cfg.Scalar != 16 || *cfg.Pointer != 16 || !reflect.DeepEqual(cfg.Slice, []int{16}) || cfg.Array != [2]uint{16, 8} {
+		t.Errorf("after Parse cfg = %+v", cfg)
+	}
+}
+
+func TestCountExplicitBool(t *testing.T) {
+	tests := []struct {
+		args []string
+		want uint8
+	}{
+		{[]string{"-v", "-v"}, 2},
+		{[]string{"-v", "-v=true"}, 2},
+		{[]string{"-v", "-v", "-v=false"}, 0},
+		{[]string{"-v=false", "-v"}, 1},
+		{[]string{"-v", "-v=5"}, 5},
+	}
+	for _, tt := range tests {
+		var cfg struct {
+			V uint8 `flag:"v" count:"true"`
+		}
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		if err := TryLoadTo(fs, "", &cfg); err != nil {
+			t.Fatalf("TryLoadTo: %v", err)
+		}
+		if err := fs.Parse(tt.args); err != nil {
+			t.Errorf("Parse(%q): %v", tt.args, err)
+			continue
+		}
+		if cfg.V != tt.want {
+			t.Errorf("Parse(%q): V = %d, want %d", tt.args, cfg.V, tt.want)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+// Package yamlfile 从 YAML 配置文件中加载 structflag 使用的结构体。
+//
+// YAML 支持放在这个单独的包中，只有导入该包的程序才会编译和链接 gopkg.in/yaml.v3。
+// 该包与 structflag 属于同一个模块，因此 gopkg.in/yaml.v3 仍出现在 structflag 模块的依赖中。
+package yamlfile
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/MUMU-DADA/structflag"
+	"gopkg.in/yaml.v3"
+)
+
+// LoadFromYAML 读取 path 指向的 YAML 文件并将其解码到 v 中，v 必须是指向结构体的非 nil 指针。
+//
+// 与 structflag.LoadFromJSON 相同，在 structflag.LoadTo 之前调用 LoadFromYAML
+// 即可让配置文件中的值成为标志的默认值，并由命令行标志覆盖：
+//
+//	if err := yamlfile.LoadFromYAML("config.yaml", &cfg); err != nil && !errors.Is(err, os.ErrNotExist) {
+//		log.Fatal(err)
+//	}
+//	structflag.Load(&cfg)
+//
+// YAML 键按照以下顺序与字段匹配：字段的 "yaml" 标签中的名称；"flag" 标签中的名称；
+// 字段名称（不区分大小写）。YAML 中的映射对应嵌套结构体，带有 `yaml:",inline"` 的字段
+// 从外层映射中解码。`yaml:"-"` 或 `flag:"-"` 的字段被忽略。
+//
+// 如果文件不存在，返回的错误满足 errors.Is(err, os.ErrNotExist)，以便调用方将配置文件视为可选。
+func LoadFromYAML(path string, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("structflag: LoadFromYAML requires a non-nil pointer to a struct, got %T", v)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("structflag: %w", err)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("structflag: decode %s: %w", path, err)
+	}
+	if len(doc.Content) == 0 {
+		return nil
+	}
+	if err := decode(doc.Content[0], rv.Elem()); err != nil {
+		return fmt.Errorf("structflag: decode %s: %w", path, err)
+	}
+	return nil
+}
+
+func init() {
+	structflag.RegisterFileFormat(".yaml", decodeMap)
+	structflag.RegisterFileFormat(".yml", decodeMap)
+}
+
+// LoadFile 与 structflag.LoadFile 相同，但读取 YAML 文件：YAML 键与 structflag 为字段生成的
+// 标志名称匹配，嵌套的映射对应嵌套结构体，序列对应切片字段。time.Duration 字段使用与命令行相同的
+// 字符串，例如 "250ms"。类型不匹配的错误信息包含完整的键路径，例如 "db.max-conns"。
+// opts 应与加载 v 时使用的相同。在 structflag.LoadTo 之前调用 LoadFile 即可让文件中的值成为标志的默认值，
+// 但带有 "default" 标签的字段仍使用标签中的值；需要文件优先时，请在 LoadTo 中使用 structflag.WithFile：
+//
+//	if err := yamlfile.LoadFile("config.yaml", &cfg); err != nil && !errors.Is(err, os.ErrNotExist) {
+//		log.Fatal(err)
+//	}
+//	structflag.Load(&cfg)
+func LoadFile(path string, v interface{}, opts ...structflag.Option) error {
+	return structflag.LoadFileWith(path, v, decodeMap, opts...)
+}
+
+// decodeMap 将 YAML 文档解码为 structflag.LoadFileWith 使用的映射。空文档解码为空映射。
+func decodeMap(data []byte) (map[string]interface{}, error) {
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// decode 将 node 解码到 val 中。映射解码到结构体时按照 LoadFromYAML 描述的规则匹配键，
+// 其他情况交给 yaml 包处理。
+func decode(node *yaml.Node, val reflect.Value) error {
+	if node.Kind != yaml.MappingNode || val.Kind() != reflect.Struct {
+		return node.Decode(val.Addr().Interface())
+	}
+	for i := 0; i < val.NumField(); i++ {
+		sf := val.Type().Field(i)
+		if sf.PkgPath != "" && !sf.Anonymous {
+			continue
+		}
+		key, inline, ok := fieldKey(sf)
+		if !ok {
+			continue
+		}
+		if inline {
+			if err := decode(node, val.Field(i)); err != nil {
+				return err
+			}
+			continue
+		}
+		if n := lookup(node, key); n != nil {
+			if err := decode(n, val.Field(i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// fieldKey 返回字段 sf 对应的 YAML 键，以及该字段是否从外层映射中解码。
+// 如果该字段应被忽略，ok 为 false。
+func fieldKey(sf reflect.StructField) (key string, inline, ok bool) {
+	if tag, has := sf.Tag.Lookup("yaml"); has {
+		parts := strings.Split(tag, ",")
+		if parts[0] == "-" && len(parts) == 1 {
+			return "", false, false
+		}
+		for _, opt := range parts[1:] {
+			if opt == "inline" {
+				return "", true, true
+			}
+		}
+		if parts[0] != "" {
+			return parts[0], false, true
+		}
+	}
+	flagValue := sf.Tag.Get("flag")
+	if flagValue == "-" {
+		return "", false, false
+	}
+	if name := strings.Split(flagValue, ",")[0]; name != "" {
+		return strings.TrimPrefix(name, "/"), false, true
+	}
+	return sf.Name, false, true
+}
+
+// lookup 返回映射 node 中键为 key 的值。精确匹配优先，否则不区分大小写地匹配。
+func lookup(node *yaml.Node, key string) *yaml.Node {
+	var fold *yaml.Node
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		k := node.Content[i].Value
+		if k == key {
+			return node.Content[i+1]
+		}
+		if fold == nil && strings.EqualFold(k, key) {
+			fold = node.Content[i+1]
+		}
+	}
+	return fold
+}
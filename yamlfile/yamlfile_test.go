@@ -0,0 +1,89 @@
+package yamlfile
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MUMU-DADA/structflag"
+)
+
+type dbConfig struct {
+	MaxConns int           `flag:"max-conns"`
+	Timeout  time.Duration `flag:"timeout"`
+}
+
+type config struct {
+	Name  string   `flag:"name"`
+	Hosts []string `flag:"hosts"`
+	DB    dbConfig `flag:"db"`
+}
+
+func writeFile(t *testing.T, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadFile(t *testing.T) {
+	path := writeFile(t, "name: api\nhosts: [a, b]\ndb:\n  max-conns: 10\n  timeout: 250ms\n")
+	var cfg config
+	if err := LoadFile(path, &cfg); err != nil {
+		t.Fatalf("LoadFile: %v", err)
+	}
+	if cfg.Name != "api" || strings.Join(cfg.Hosts, ",") != "a,b" || cfg.DB.MaxConns != 10 || cfg.DB.Timeout != 250*time.Millisecond {
+		t.Errorf("cfg = %+v", cfg)
+	}
+}
+
+func TestLoadFileErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []string
+	}{
+		{"key path", "db:\n  max-conns: many\n", []string{"db.max-conns", "invalid int"}},
+		{"float for int", "db:\n  max-conns: 1.5\n", []string{"db.max-conns", "float 1.5 for integer type int"}},
+		{"invalid duration", "db:\n  timeout: 250\n", []string{"db.timeout", "missing unit"}},
+		{"unknown keys", "nme: x\ndb:\n  max: 1\n", []string{"unknown keys", "db.max, nme"}},
+		{"syntax", "db: [\n", []string{"line 1"}},
+	}
+	for _, tt := range tests {
+		var cfg config
+		err := LoadFile(writeFile(t, tt.data), &cfg)
+		if err == nil {
+			t.Errorf("%s: LoadFile succeeded", tt.name)
+			continue
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("%s: LoadFile = %v, want an error containing %q", tt.name, err, want)
+			}
+		}
+	}
+}
+
+func TestLoadFileIgnoreUnknownKeys(t *testing.T) {
+	path := writeFile(t, "nme: x\ndb:\n  max-conns: 3\n")
+	var cfg config
+	if err := LoadFile(path, &cfg, structflag.IgnoreUnknownKeys()); err != nil {
+		t.Fatalf("LoadFile: %v", err)
+	}
+	if cfg.DB.MaxConns != 3 {
+		t.Errorf("MaxConns = %d, want 3", cfg.DB.MaxConns)
+	}
+}
+
+func TestLoadFileNotExist(t *testing.T) {
+	var cfg config
+	err := LoadFile(filepath.Join(t.TempDir(), "missing.yaml"), &cfg)
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("LoadFile = %v, want an error satisfying errors.Is(err, os.ErrNotExist)", err)
+	}
+}